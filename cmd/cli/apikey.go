@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/database"
+	customerrors "github.com/axellelanca/urlshortener/internal/errors"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+)
+
+// apiKeyNameFlag stores the label of the key to create via the --name flag
+var apiKeyNameFlag string
+
+// apiKeyScopesFlag stores the scopes of the key to create via the --scopes flag
+var apiKeyScopesFlag []string
+
+// apiKeyWorkspaceFlag stores the slug of the workspace the key to create acts on, via the --workspace flag
+var apiKeyWorkspaceFlag string
+
+// apiKeyIDFlag stores the ID of the key to revoke via the --id flag
+var apiKeyIDFlag uint
+
+// APIKeyCmd groups the commands managing the API keys of the management API
+var APIKeyCmd = &cobra.Command{
+	Use:   "apikey",
+	Short: "Manages the API keys required by the /api/v1 endpoints.",
+}
+
+// APIKeyCreateCmd represents the 'apikey create' command
+var APIKeyCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Creates a new API key and prints it once.",
+	Long: `This command generates a new API key with the given scopes and prints it.
+Only a hash of the key is stored: copy it now, it cannot be displayed again.
+The key is bound to one workspace: it only sees and creates the links of that workspace.
+
+Scopes:
+  create      create links (POST /api/v1/links)
+  read-stats  list links and read their statistics
+  admin       update, disable, enable and delete links; implies every other scope
+
+Examples:
+  url-shortener apikey create --name="ci-pipeline" --scopes=create
+  url-shortener apikey create --name="dashboard" --scopes=read-stats
+  url-shortener apikey create --name="ops" --scopes=admin
+  url-shortener apikey create --name="acme-ci" --scopes=create,read-stats --workspace=acme`,
+	Run: runAPIKeyCreate,
+}
+
+// APIKeyListCmd represents the 'apikey list' command
+var APIKeyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the API keys, revoked ones included.",
+	Run:   runAPIKeyList,
+}
+
+// APIKeyRevokeCmd represents the 'apikey revoke' command
+var APIKeyRevokeCmd = &cobra.Command{
+	Use:   "revoke",
+	Short: "Revokes an API key so it can no longer be used.",
+	Long: `This command revokes the API key with the given ID (see 'apikey list').
+Requests using it are rejected immediately, including by a running server.`,
+	Run: runAPIKeyRevoke,
+}
+
+func init() {
+	APIKeyCreateCmd.Flags().StringVar(&apiKeyNameFlag, "name", "", "Label identifying who or what uses the key")
+	APIKeyCreateCmd.Flags().StringSliceVar(&apiKeyScopesFlag, "scopes", nil, "Comma-separated scopes: create, read-stats, admin")
+	APIKeyCreateCmd.Flags().StringVar(&apiKeyWorkspaceFlag, "workspace", models.DefaultWorkspace, "Slug of the workspace the key acts on")
+	APIKeyCreateCmd.MarkFlagRequired("name")
+	APIKeyCreateCmd.MarkFlagRequired("scopes")
+
+	APIKeyRevokeCmd.Flags().UintVar(&apiKeyIDFlag, "id", 0, "The ID of the key to revoke")
+	APIKeyRevokeCmd.MarkFlagRequired("id")
+
+	// Register the subcommands under 'apikey', then 'apikey' with the root command
+	APIKeyCmd.AddCommand(APIKeyCreateCmd, APIKeyListCmd, APIKeyRevokeCmd)
+	cmd.RootCmd.AddCommand(APIKeyCmd)
+}
+
+// runAPIKeyCreate executes the logic for the 'apikey create' command
+func runAPIKeyCreate(cmd *cobra.Command, args []string) {
+	apiKeyService, sqlDB := openAPIKeyService()
+	defer sqlDB.Close()
+
+	plainKey, key, err := apiKeyService.CreateAPIKey(apiKeyNameFlag, apiKeyWorkspaceFlag, apiKeyScopesFlag)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ API key created (ID %d, workspace: %s, scopes: %s)\n", key.ID, key.Workspace, strings.Join(key.ScopeList(), ", "))
+	fmt.Printf("   %s\n", plainKey)
+	fmt.Println("⚠️  Copy this key now: it is stored hashed and cannot be displayed again.")
+}
+
+// runAPIKeyList executes the logic for the 'apikey list' command
+func runAPIKeyList(cmd *cobra.Command, args []string) {
+	apiKeyService, sqlDB := openAPIKeyService()
+	defer sqlDB.Close()
+
+	keys, err := apiKeyService.ListAPIKeys()
+	if err != nil {
+		fmt.Printf("Error listing API keys: %v\n", err)
+		os.Exit(1)
+	}
+	if len(keys) == 0 {
+		fmt.Println("No API keys. Create one with 'apikey create'.")
+		return
+	}
+
+	fmt.Printf("%-5s %-20s %-14s %-16s %-24s %-20s %-20s %s\n", "ID", "NAME", "PREFIX", "WORKSPACE", "SCOPES", "CREATED", "LAST USED", "STATUS")
+	for _, key := range keys {
+		lastUsed := "never"
+		if key.LastUsedAt != nil {
+			lastUsed = key.LastUsedAt.Format("2006-01-02 15:04:05")
+		}
+		status := "active"
+		if key.Revoked() {
+			status = "revoked " + key.RevokedAt.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("%-5d %-20s %-14s %-16s %-24s %-20s %-20s %s\n", key.ID, key.Name, key.Prefix+"…",
+			key.Workspace, key.Scopes, key.CreatedAt.Format("2006-01-02 15:04:05"), lastUsed, status)
+	}
+}
+
+// runAPIKeyRevoke executes the logic for the 'apikey revoke' command
+func runAPIKeyRevoke(cmd *cobra.Command, args []string) {
+	apiKeyService, sqlDB := openAPIKeyService()
+	defer sqlDB.Close()
+
+	key, err := apiKeyService.RevokeAPIKey(apiKeyIDFlag)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrAPIKeyNotFound) {
+			fmt.Printf("Error: API key %d not found\n", apiKeyIDFlag)
+		} else {
+			fmt.Printf("Error revoking API key: %v\n", err)
+		}
+		os.Exit(1)
+	}
+	fmt.Printf("⛔ API key %d (%s) revoked\n", key.ID, key.Name)
+}
+
+// openAPIKeyService connects to the configured database and builds the API key service
+// shared by the apikey subcommands. The caller must close the returned connection.
+func openAPIKeyService() (*services.APIKeyService, *sql.DB) {
+	// Load application configuration to get database settings
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Open the configured database (SQLite or PostgreSQL) through the shared connection factory
+	db, err := database.Open(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	// Get underlying SQL connection for proper cleanup
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("FATAL: Failed to get underlying SQL database: %v", err)
+	}
+
+	// The keys and workspaces tables may not exist yet on a database created before them
+	if err := database.AutoMigrate(db); err != nil {
+		log.Fatalf("Failed to migrate the database: %v", err)
+	}
+
+	return services.NewAPIKeyService(repository.NewAPIKeyRepository(db), repository.NewWorkspaceRepository(db)), sqlDB
+}
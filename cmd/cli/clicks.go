@@ -0,0 +1,83 @@
+package cli
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/workers"
+	"github.com/spf13/cobra"
+)
+
+// ClicksCmd groups the maintenance commands for recorded clicks
+var ClicksCmd = &cobra.Command{
+	Use:   "clicks",
+	Short: "Maintenance commands for click tracking.",
+}
+
+// ReplayDeadLettersCmd represents the 'clicks replay-dead-letters' command
+// This command re-inserts the clicks that the server workers could not write even after retries
+var ReplayDeadLettersCmd = &cobra.Command{
+	Use:   "replay-dead-letters",
+	Short: "Re-inserts clicks from the dead-letter file into the database.",
+	Long: `This command reads the dead-letter file (analytics.dead_letter_path), where click workers
+put the clicks they failed to write after all retries, and inserts them into the database.
+Clicks that fail again are kept in the file with their new failure reason.
+It can be run while the server is running.`,
+	Run: runReplayDeadLetters,
+}
+
+func init() {
+	// Register the subcommand under 'clicks', then 'clicks' with the root command
+	ClicksCmd.AddCommand(ReplayDeadLettersCmd)
+	cmd.RootCmd.AddCommand(ClicksCmd)
+}
+
+// runReplayDeadLetters executes the logic for the replay-dead-letters command
+func runReplayDeadLetters(cmd *cobra.Command, args []string) {
+	// Load application configuration to get database and dead-letter settings
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+	if cfg.Analytics.DeadLetterPath == "" {
+		fmt.Println("Error: analytics.dead_letter_path is not set, there is no dead-letter file to replay")
+		os.Exit(1)
+	}
+
+	// Open the configured database (SQLite or PostgreSQL) through the shared connection factory
+	db, err := database.Open(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	// Get underlying SQL connection for proper cleanup
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("FATAL: Failed to get underlying SQL database: %v", err)
+	}
+	defer sqlDB.Close() // Ensure database connection is closed
+
+	clickRepo := repository.NewClickRepository(db)
+	deadLetters := workers.NewDeadLetterStore(cfg.Analytics.DeadLetterPath)
+
+	replayed, failed, err := deadLetters.Replay(clickRepo)
+	if err != nil {
+		fmt.Printf("Error replaying dead letters: %v\n", err)
+		os.Exit(1)
+	}
+
+	if replayed == 0 && failed == 0 {
+		fmt.Printf("No dead-lettered clicks in %s\n", cfg.Analytics.DeadLetterPath)
+		return
+	}
+	fmt.Printf("✅ %d click(s) replayed\n", replayed)
+	if failed > 0 {
+		fmt.Printf("⚠️  %d click(s) failed again and were kept in %s\n", failed, cfg.Analytics.DeadLetterPath)
+		os.Exit(1)
+	}
+}
@@ -1,25 +1,50 @@
 package cli
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/axellelanca/urlshortener/cmd"
 	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
-	"github.com/glebarez/sqlite"
 	"github.com/spf13/cobra"
-	"gorm.io/gorm"
 )
 
 // longURLFlag stores the URLs provided by the user via the --url flag
 var longURLFlag string
 
+// aliasFlag stores the optional custom short code provided via the --alias flag
+var aliasFlag string
+
+// expiresAtFlag stores the optional expiration date (RFC 3339) provided via the --expires-at flag
+var expiresAtFlag string
+
+// expiresInFlag stores the optional lifetime (e.g. "72h") provided via the --expires-in flag
+var expiresInFlag time.Duration
+
+// maxClicksFlag stores the optional click budget provided via the --max-clicks flag
+var maxClicksFlag int
+
+// redirectStatusFlag stores the optional redirect status provided via the --redirect-status flag
+var redirectStatusFlag int
+
+// dedupeFlag stores whether an existing link with the same destination is returned instead of a new one,
+// provided via the --dedupe flag; server.deduplicate_links applies when the flag is not set
+var dedupeFlag bool
+
+// createWorkspaceFlag stores the slug of the workspace owning the new links, provided via the --workspace flag
+var createWorkspaceFlag string
+
 // CreateCmd represents the 'create' command for the CLI application
 // This command allows users to create shortened URLs from one or more long URLs via command line
 var CreateCmd = &cobra.Command{
@@ -29,11 +54,16 @@ var CreateCmd = &cobra.Command{
 
 Examples:
   url-shortener create --url="https://www.google.com"
+  url-shortener create --url="https://www.example.com/sales" --alias="spring-sale"
+  url-shortener create --url="https://www.example.com/promo" --expires-in=72h --max-clicks=100
+  url-shortener create --url="https://www.example.com/about" --redirect-status=301
+  url-shortener create --url="https://www.example.com/launch" --workspace=acme --alias="launch"
+  url-shortener create --url="https://www.example.com/pricing" --dedupe
   url-shortener create --url="https://www.google.com" --url="https://www.github.com"
   url-shortener create --url='["https://www.google.com", "https://www.github.com", "https://www.stackoverflow.com"]'
   url-shortener create --url="['https://www.google.com','https://www.github.com']"`,
 
-	Run: func(cmd *cobra.Command, args []string) {
+	Run: func(command *cobra.Command, args []string) {
 		// Validate that the --url flag has been provided
 		if longURLFlag == "" {
 			fmt.Println("Error: The --url flag is required")
@@ -47,6 +77,31 @@ Examples:
 			os.Exit(1)
 		}
 
+		// A custom alias identifies exactly one link, so it cannot be shared by several URLs
+		if aliasFlag != "" && len(allURLs) > 1 {
+			fmt.Println("Error: The --alias flag can only be used with a single URL")
+			os.Exit(1)
+		}
+
+		// Resolve the optional settings shared by every created link
+		opts := services.CreateLinkOptions{
+			Workspace:      createWorkspaceFlag,
+			Alias:          aliasFlag,
+			MaxClicks:      maxClicksFlag,
+			RedirectStatus: redirectStatusFlag,
+		}
+		if expiresAtFlag != "" {
+			expiresAt, err := time.Parse(time.RFC3339, expiresAtFlag)
+			if err != nil {
+				fmt.Printf("Error: Invalid --expires-at value '%s' (expected RFC 3339, e.g. 2030-01-01T00:00:00Z): %v\n", expiresAtFlag, err)
+				os.Exit(1)
+			}
+			opts.ExpiresAt = &expiresAt
+		} else if expiresInFlag > 0 {
+			expiresAt := time.Now().Add(expiresInFlag)
+			opts.ExpiresAt = &expiresAt
+		}
+
 		// Validate all parsed URLs before processing any of them
 		for i, urlStr := range allURLs {
 			_, err := url.ParseRequestURI(urlStr)
@@ -62,8 +117,20 @@ Examples:
 			log.Fatalf("Failed to load configuration: %v", err)
 		}
 
-		// Initialize database connection using GORM with SQLite driver
-		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		// The --dedupe flag overrides the configured default in both directions
+		opts.Deduplicate = cfg.Server.DeduplicateLinks
+		if command.Flags().Changed("dedupe") {
+			opts.Deduplicate = dedupeFlag
+		}
+
+		// Apply the same destination policy as the API (schemes, domain lists, private networks)
+		policy, err := cmd.NewDestinationPolicy(cfg)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+
+		// Open the configured database (SQLite or PostgreSQL) through the shared connection factory
+		db, err := database.Open(cfg)
 		if err != nil {
 			log.Fatalf("Failed to connect to database: %v", err)
 		}
@@ -77,7 +144,8 @@ Examples:
 
 		// Initialize the repository and service layers
 		linkRepo := repository.NewLinkRepository(db)
-		linkService := services.NewLinkService(linkRepo)
+		workspaceRepo := repository.NewWorkspaceRepository(db)
+		linkService := services.NewLinkService(linkRepo, workspaceRepo, policy, cmd.NewURLNormalizer(cfg))
 
 		// Process each URL and collect results
 		fmt.Printf("Creating short URLs for %d URL(s)...\n\n", len(allURLs))
@@ -87,19 +155,36 @@ Examples:
 			fmt.Printf("[%d/%d] Processing: %s\n", i+1, len(allURLs), longURL)
 
 			// Call the LinkService to create the shortened link
-			link, err := linkService.CreateLink(longURL)
+			link, created, err := linkService.CreateLink(context.Background(), longURL, opts)
 			if err != nil {
 				fmt.Printf("  ❌ Failed to create short link: %v\n\n", err)
 				continue
 			}
 
 			// Build the full shortened URL using the base URL from configuration
-			fullShortURL := fmt.Sprintf("%s/%s", cfg.Server.BaseURL, link.ShortCode)
+			fullShortURL := fmt.Sprintf("%s/%s", cfg.Server.BaseURL, link.Path())
 
 			// Display the results for this URL
-			fmt.Printf("  ✅ Short URL created successfully:\n")
+			if created {
+				fmt.Printf("  ✅ Short URL created successfully:\n")
+			} else {
+				fmt.Printf("  ♻️  Existing short URL reused (same destination):\n")
+			}
 			fmt.Printf("     Code: %s\n", link.ShortCode)
-			fmt.Printf("     Full URL: %s\n\n", fullShortURL)
+			fmt.Printf("     Full URL: %s\n", fullShortURL)
+			if link.LongURL != longURL {
+				fmt.Printf("     Destination: %s\n", link.LongURL)
+			}
+			if link.ExpiresAt != nil {
+				fmt.Printf("     Expires at: %s\n", link.ExpiresAt.Format("2006-01-02 15:04:05"))
+			}
+			if link.MaxClicks > 0 {
+				fmt.Printf("     Max clicks: %d\n", link.MaxClicks)
+			}
+			if link.RedirectStatus != 0 {
+				fmt.Printf("     Redirect status: %d\n", link.RedirectStatus)
+			}
+			fmt.Println()
 
 			successCount++
 		}
@@ -114,99 +199,99 @@ Examples:
 }
 
 // parseURLFlag parses a URL flag that can be either a single URL string or a JSON array of URLs
-// parseURLFlag parses a URL flag that can be either a single URL string or a JSON array of URLs
+// Its trace is only output with logging.level set to "debug".
 func parseURLFlag(urlFlag string) ([]string, error) {
-	log.Printf("DEBUG: parseURLFlag called with input: '%s'", urlFlag)
+	slog.Debug("parseURLFlag called", "input", urlFlag)
 
 	// Trim whitespace
 	urlFlag = strings.TrimSpace(urlFlag)
-	log.Printf("DEBUG: After trimming whitespace: '%s'", urlFlag)
+	slog.Debug("After trimming whitespace", "input", urlFlag)
 
 	// Check if it looks like a JSON array (starts with [ and ends with ])
 	if strings.HasPrefix(urlFlag, "[") && strings.HasSuffix(urlFlag, "]") {
-		log.Printf("DEBUG: Input appears to be JSON array format")
+		slog.Debug("Input appears to be JSON array format")
 
 		// First try to parse as proper JSON array (with double quotes)
 		var urls []string
-		log.Printf("DEBUG: Attempting to parse as standard JSON array...")
+		slog.Debug("Attempting to parse as standard JSON array")
 		err := json.Unmarshal([]byte(urlFlag), &urls)
 		if err == nil {
-			log.Printf("DEBUG: Successfully parsed as JSON array, found %d URLs: %v", len(urls), urls)
+			slog.Debug("Successfully parsed as JSON array", "count", len(urls), "urls", urls)
 			if len(urls) == 0 {
-				log.Printf("ERROR: JSON array is empty")
+				slog.Debug("JSON array is empty")
 				return nil, fmt.Errorf("JSON array cannot be empty")
 			}
-			log.Printf("DEBUG: Returning successfully parsed JSON array")
+			slog.Debug("Returning successfully parsed JSON array")
 			return urls, nil
 		}
-		log.Printf("DEBUG: Standard JSON parsing failed: %v", err)
+		slog.Debug("Standard JSON parsing failed", "error", err)
 
 		// If JSON parsing fails, try to convert single quotes to double quotes and parse again
 		normalizedJSON := strings.ReplaceAll(urlFlag, "'", "\"")
-		log.Printf("DEBUG: Attempting to parse with normalized quotes: '%s'", normalizedJSON)
+		slog.Debug("Attempting to parse with normalized quotes", "input", normalizedJSON)
 		err = json.Unmarshal([]byte(normalizedJSON), &urls)
 		if err == nil {
-			log.Printf("DEBUG: Successfully parsed normalized JSON, found %d URLs: %v", len(urls), urls)
+			slog.Debug("Successfully parsed normalized JSON", "count", len(urls), "urls", urls)
 			if len(urls) == 0 {
-				log.Printf("ERROR: Normalized JSON array is empty")
+				slog.Debug("Normalized JSON array is empty")
 				return nil, fmt.Errorf("JSON array cannot be empty")
 			}
-			log.Printf("DEBUG: Returning successfully parsed normalized JSON array")
+			slog.Debug("Returning successfully parsed normalized JSON array")
 			return urls, nil
 		}
-		log.Printf("DEBUG: Normalized JSON parsing also failed: %v", err)
+		slog.Debug("Normalized JSON parsing also failed", "error", err)
 
 		// If both JSON attempts fail, manually parse comma-separated values
-		log.Printf("DEBUG: Attempting manual parsing of array content...")
+		slog.Debug("Attempting manual parsing of array content")
 		// Remove the outer brackets first
 		content := strings.TrimSpace(urlFlag[1 : len(urlFlag)-1])
-		log.Printf("DEBUG: Content after removing brackets: '%s'", content)
+		slog.Debug("Content after removing brackets", "content", content)
 		if content == "" {
-			log.Printf("ERROR: Array content is empty after removing brackets")
+			slog.Debug("Array content is empty after removing brackets")
 			return nil, fmt.Errorf("JSON array cannot be empty")
 		}
 
 		// Split by comma and clean each URL
 		parts := strings.Split(content, ",")
-		log.Printf("DEBUG: Split by comma into %d parts: %v", len(parts), parts)
+		slog.Debug("Split by comma", "count", len(parts), "parts", parts)
 		var parsedURLs []string
 		for i, part := range parts {
-			log.Printf("DEBUG: Processing part %d: '%s'", i+1, part)
+			slog.Debug("Processing part", "index", i+1, "part", part)
 
 			// Trim whitespace
 			cleanURL := strings.TrimSpace(part)
-			log.Printf("DEBUG: After trimming whitespace: '%s'", cleanURL)
+			slog.Debug("After trimming whitespace", "url", cleanURL)
 
 			// Remove surrounding quotes (both single and double)
 			cleanURL = removeQuotes(cleanURL)
-			log.Printf("DEBUG: After removing quotes: '%s'", cleanURL)
+			slog.Debug("After removing quotes", "url", cleanURL)
 
 			// Trim again after quote removal
 			cleanURL = strings.TrimSpace(cleanURL)
-			log.Printf("DEBUG: After final trim: '%s'", cleanURL)
+			slog.Debug("After final trim", "url", cleanURL)
 
 			if cleanURL != "" {
 				parsedURLs = append(parsedURLs, cleanURL)
-				log.Printf("DEBUG: Added URL to result: '%s'", cleanURL)
+				slog.Debug("Added URL to result", "url", cleanURL)
 			} else {
-				log.Printf("DEBUG: Skipping empty URL after cleaning")
+				slog.Debug("Skipping empty URL after cleaning")
 			}
 		}
 
-		log.Printf("DEBUG: Manual parsing completed, found %d valid URLs: %v", len(parsedURLs), parsedURLs)
+		slog.Debug("Manual parsing completed", "count", len(parsedURLs), "urls", parsedURLs)
 		if len(parsedURLs) == 0 {
-			log.Printf("ERROR: No valid URLs found after manual parsing")
+			slog.Debug("No valid URLs found after manual parsing")
 			return nil, fmt.Errorf("no valid URLs found in array")
 		}
 
-		log.Printf("DEBUG: Returning manually parsed URLs")
+		slog.Debug("Returning manually parsed URLs")
 		return parsedURLs, nil
 	}
 
 	// Not a JSON array, treat as single URL
-	log.Printf("DEBUG: Input is not JSON array format, treating as single URL")
+	slog.Debug("Input is not JSON array format, treating as single URL")
 	result := []string{urlFlag}
-	log.Printf("DEBUG: Returning single URL result: %v", result)
+	slog.Debug("Returning single URL result", "urls", result)
 	return result, nil
 }
 
@@ -241,6 +326,20 @@ func init() {
 	// This allows JSON arrays or single URLs to be specified
 	CreateCmd.Flags().StringVar(&longURLFlag, "url", "", "The long URL(s) to shorten (single URL or JSON array)")
 
+	// Define the optional --alias flag to choose a readable short code instead of a random one
+	CreateCmd.Flags().StringVar(&aliasFlag, "alias", "", "Custom short code to use instead of a generated one (single URL only)")
+
+	// Define the optional expiration flags: by date, by lifetime, and by click budget
+	CreateCmd.Flags().StringVar(&expiresAtFlag, "expires-at", "", "Expiration date in RFC 3339 format (e.g. 2030-01-01T00:00:00Z)")
+	CreateCmd.Flags().DurationVar(&expiresInFlag, "expires-in", 0, "Lifetime of the link from now (e.g. 72h)")
+	CreateCmd.Flags().IntVar(&maxClicksFlag, "max-clicks", 0, "Number of clicks after which the link expires (0 = unlimited)")
+	CreateCmd.MarkFlagsMutuallyExclusive("expires-at", "expires-in")
+
+	// Define the optional redirect status flag (0 keeps the server default)
+	CreateCmd.Flags().IntVar(&redirectStatusFlag, "redirect-status", 0, "HTTP redirect status: 301, 302, 307 or 308 (default: server setting)")
+	CreateCmd.Flags().BoolVar(&dedupeFlag, "dedupe", false, "Reuse the existing link of the workspace for an identical URL (default: server.deduplicate_links)")
+	CreateCmd.Flags().StringVar(&createWorkspaceFlag, "workspace", models.DefaultWorkspace, "Slug of the workspace owning the new links")
+
 	// Mark the flag as required - Cobra will enforce this
 	CreateCmd.MarkFlagRequired("url")
 
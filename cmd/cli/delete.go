@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/database"
+	customerrors "github.com/axellelanca/urlshortener/internal/errors"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+)
+
+// deleteCodeFlag stores the short code of the link to delete, provided via the --code flag
+var deleteCodeFlag string
+
+// deleteWorkspaceFlag stores the slug of the workspace owning the link, provided via the --workspace flag
+var deleteWorkspaceFlag string
+
+// DeleteCmd represents the 'delete' command
+// This command soft-deletes a link: it stops resolving but its click history is kept
+var DeleteCmd = &cobra.Command{
+	Use:   "delete",
+	Short: "Deletes a short URL.",
+	Long: `This command deletes a short URL. The deletion is soft: the link stops redirecting
+and disappears from the API, but its click history stays in the database and its
+short code is never reassigned to another link.`,
+	Run: runDelete,
+}
+
+func init() {
+	// Define the required --code flag for the delete command
+	DeleteCmd.Flags().StringVar(&deleteCodeFlag, "code", "", "The short code of the link to delete")
+	DeleteCmd.MarkFlagRequired("code")
+	DeleteCmd.Flags().StringVar(&deleteWorkspaceFlag, "workspace", models.DefaultWorkspace, "Slug of the workspace owning the link")
+
+	// Register this command with the root command
+	cmd.RootCmd.AddCommand(DeleteCmd)
+}
+
+// runDelete executes the logic for the delete command
+func runDelete(cmd *cobra.Command, args []string) {
+	// Load application configuration to get database settings
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Open the configured database (SQLite or PostgreSQL) through the shared connection factory
+	db, err := database.Open(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	// Get underlying SQL connection for proper cleanup
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("FATAL: Failed to get underlying SQL database: %v", err)
+	}
+	defer sqlDB.Close() // Ensure database connection is closed
+
+	// Initialize repository and service layers
+	linkRepo := repository.NewLinkRepository(db)
+	linkService := services.NewLinkService(linkRepo, repository.NewWorkspaceRepository(db), nil, nil)
+
+	if err := linkService.DeleteLink(deleteWorkspaceFlag, deleteCodeFlag); err != nil {
+		if errors.Is(err, customerrors.ErrShortCodeNotFound) {
+			fmt.Printf("Error: Short code '%s' not found\n", deleteCodeFlag)
+		} else {
+			fmt.Printf("Error deleting link: %v\n", err)
+		}
+		os.Exit(1)
+	}
+
+	fmt.Printf("🗑️  Short code '%s' deleted (click history kept)\n", deleteCodeFlag)
+}
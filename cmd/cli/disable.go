@@ -0,0 +1,102 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/database"
+	customerrors "github.com/axellelanca/urlshortener/internal/errors"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+)
+
+// disableCodeFlag stores the short code provided to the disable/enable commands via the --code flag
+var disableCodeFlag string
+
+// disableWorkspaceFlag stores the slug of the workspace owning the link, provided via the --workspace flag
+var disableWorkspaceFlag string
+
+// DisableCmd represents the 'disable' command
+// This command turns a link off without deleting it
+var DisableCmd = &cobra.Command{
+	Use:   "disable",
+	Short: "Disables a short URL so it stops redirecting.",
+	Long: `This command turns a short URL off. The link and its click history are kept,
+and it can be turned back on at any time with the 'enable' command.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runSetDisabled(true)
+	},
+}
+
+// EnableCmd represents the 'enable' command
+// This command turns a previously disabled link back on
+var EnableCmd = &cobra.Command{
+	Use:   "enable",
+	Short: "Re-enables a previously disabled short URL.",
+	Long:  `This command turns a disabled short URL back on so it redirects again.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		runSetDisabled(false)
+	},
+}
+
+func init() {
+	// Both commands share the same --code flag variable since only one of them runs at a time
+	DisableCmd.Flags().StringVar(&disableCodeFlag, "code", "", "The short code of the link to disable")
+	DisableCmd.MarkFlagRequired("code")
+	EnableCmd.Flags().StringVar(&disableCodeFlag, "code", "", "The short code of the link to enable")
+	EnableCmd.MarkFlagRequired("code")
+	DisableCmd.Flags().StringVar(&disableWorkspaceFlag, "workspace", models.DefaultWorkspace, "Slug of the workspace owning the link")
+	EnableCmd.Flags().StringVar(&disableWorkspaceFlag, "workspace", models.DefaultWorkspace, "Slug of the workspace owning the link")
+
+	// Register both commands with the root command
+	cmd.RootCmd.AddCommand(DisableCmd)
+	cmd.RootCmd.AddCommand(EnableCmd)
+}
+
+// runSetDisabled executes the shared logic of the disable and enable commands
+func runSetDisabled(disabled bool) {
+	// Load application configuration to get database settings
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Open the configured database (SQLite or PostgreSQL) through the shared connection factory
+	db, err := database.Open(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	// Get underlying SQL connection for proper cleanup
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("FATAL: Failed to get underlying SQL database: %v", err)
+	}
+	defer sqlDB.Close() // Ensure database connection is closed
+
+	// Initialize repository and service layers
+	linkRepo := repository.NewLinkRepository(db)
+	linkService := services.NewLinkService(linkRepo, repository.NewWorkspaceRepository(db), nil, nil)
+
+	link, err := linkService.SetLinkDisabled(disableWorkspaceFlag, disableCodeFlag, disabled)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrShortCodeNotFound) {
+			fmt.Printf("Error: Short code '%s' not found\n", disableCodeFlag)
+		} else {
+			fmt.Printf("Error updating link: %v\n", err)
+		}
+		os.Exit(1)
+	}
+
+	if link.Disabled {
+		fmt.Printf("⛔ Short code '%s' is now disabled\n", link.ShortCode)
+	} else {
+		fmt.Printf("✅ Short code '%s' is now enabled\n", link.ShortCode)
+	}
+}
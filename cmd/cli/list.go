@@ -0,0 +1,244 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/database"
+	customerrors "github.com/axellelanca/urlshortener/internal/errors"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+)
+
+// Flags of the list command
+var (
+	listLimitFlag         int    // Page size
+	listCursorFlag        string // Cursor printed by the previous page
+	listSortFlag          string // created_at or clicks
+	listAscFlag           bool   // Sort in ascending order instead of descending
+	listDomainFlag        string // Destination domain filter
+	listCreatedAfterFlag  string // Lower bound on creation date
+	listCreatedBeforeFlag string // Upper bound on creation date
+	listHealthFlag        string // Health status filter
+	listFormatFlag        string // table, json or csv
+	listWorkspaceFlag     string // Workspace filter; empty lists every workspace
+)
+
+// ListCmd represents the 'list' command
+// This command lists links page by page with sorting and filtering options
+var ListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists short URLs with pagination, sorting and filters.",
+	Long: `This command lists the registered short URLs one page at a time.
+When more links are available, the cursor of the next page is printed (to stderr for
+json/csv output) and can be passed back with --cursor.
+
+Examples:
+  url-shortener list
+  url-shortener list --sort=clicks --limit=10
+  url-shortener list --workspace=acme
+  url-shortener list --domain=example.com --health=down --format=csv
+  url-shortener list --created-after=2025-01-01 --created-before=2025-02-01 --format=json`,
+	Run: runList,
+}
+
+func init() {
+	ListCmd.Flags().IntVar(&listLimitFlag, "limit", 20, "Number of links per page (1-100)")
+	ListCmd.Flags().StringVar(&listCursorFlag, "cursor", "", "Cursor of the page to display, as printed by the previous page")
+	ListCmd.Flags().StringVar(&listSortFlag, "sort", repository.SortByCreatedAt, "Sort key: created_at or clicks")
+	ListCmd.Flags().BoolVar(&listAscFlag, "asc", false, "Sort in ascending order (default is descending)")
+	ListCmd.Flags().StringVar(&listDomainFlag, "domain", "", "Only links whose destination is on this domain (subdomains included)")
+	ListCmd.Flags().StringVar(&listCreatedAfterFlag, "created-after", "", "Only links created at or after this date (YYYY-MM-DD or RFC 3339)")
+	ListCmd.Flags().StringVar(&listCreatedBeforeFlag, "created-before", "", "Only links created before this date (YYYY-MM-DD or RFC 3339)")
+	ListCmd.Flags().StringVar(&listHealthFlag, "health", "", "Only links with this health status: unknown, up or down")
+	ListCmd.Flags().StringVar(&listFormatFlag, "format", "table", "Output format: table, json or csv")
+	ListCmd.Flags().StringVar(&listWorkspaceFlag, "workspace", "", "Only links of this workspace (default: every workspace)")
+
+	// Register this command with the root command
+	cmd.RootCmd.AddCommand(ListCmd)
+}
+
+// runList executes the logic for the list command
+func runList(cmd *cobra.Command, args []string) {
+	if listFormatFlag != "table" && listFormatFlag != "json" && listFormatFlag != "csv" {
+		fmt.Printf("Error: Unknown --format '%s' (expected table, json or csv)\n", listFormatFlag)
+		os.Exit(1)
+	}
+
+	opts := services.ListLinksOptions{
+		SortBy:    listSortFlag,
+		Ascending: listAscFlag,
+		Limit:     listLimitFlag,
+		Cursor:    listCursorFlag,
+		Filter: repository.LinkListFilter{
+			Workspace:    listWorkspaceFlag,
+			Domain:       listDomainFlag,
+			HealthStatus: listHealthFlag,
+		},
+	}
+	var err error
+	if opts.Filter.CreatedAfter, err = services.ParseOptionalDate(listCreatedAfterFlag); err != nil {
+		fmt.Printf("Error: Invalid --created-after value '%s': %v\n", listCreatedAfterFlag, err)
+		os.Exit(1)
+	}
+	if opts.Filter.CreatedBefore, err = services.ParseOptionalDate(listCreatedBeforeFlag); err != nil {
+		fmt.Printf("Error: Invalid --created-before value '%s': %v\n", listCreatedBeforeFlag, err)
+		os.Exit(1)
+	}
+
+	// Load application configuration to get database settings
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Open the configured database (SQLite or PostgreSQL) through the shared connection factory
+	db, err := database.Open(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	// Get underlying SQL connection for proper cleanup
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("FATAL: Failed to get underlying SQL database: %v", err)
+	}
+	defer sqlDB.Close() // Ensure database connection is closed
+
+	// Initialize repository and service layers
+	linkRepo := repository.NewLinkRepository(db)
+	linkService := services.NewLinkService(linkRepo, repository.NewWorkspaceRepository(db), nil, nil)
+
+	page, err := linkService.ListLinks(opts)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrInvalidListQuery) {
+			fmt.Printf("Error: %v\n", err)
+		} else {
+			fmt.Printf("Error listing links: %v\n", err)
+		}
+		os.Exit(1)
+	}
+
+	switch listFormatFlag {
+	case "json":
+		printLinksJSON(page)
+	case "csv":
+		printLinksCSV(page)
+	default:
+		printLinksTable(page)
+	}
+}
+
+// printLinksTable displays a page of links as an aligned text table
+func printLinksTable(page *services.LinkPage) {
+	if len(page.Links) == 0 {
+		fmt.Println("No links found.")
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CODE\tWORKSPACE\tCLICKS\tHEALTH\tSTATUS\tCREATED\tLONG URL")
+	for _, link := range page.Links {
+		// Codes of namespaced workspaces are shown with their namespace, as they appear in the short URL
+		fmt.Fprintf(w, "%s\t%s\t%d\t%s\t%s\t%s\t%s\n",
+			link.Path(), link.Workspace, link.ClickCount, link.HealthStatus, linkStatus(link),
+			link.CreatedAt.Format("2006-01-02 15:04"), link.LongURL)
+	}
+	w.Flush()
+
+	if page.NextCursor != "" {
+		fmt.Printf("\nMore links available. Next page: --cursor=%s\n", page.NextCursor)
+	}
+}
+
+// printLinksJSON writes a page of links as a JSON document on stdout
+func printLinksJSON(page *services.LinkPage) {
+	type jsonLink struct {
+		ShortCode    string     `json:"short_code"`
+		Workspace    string     `json:"workspace"`
+		ShortPath    string     `json:"short_path"`
+		LongURL      string     `json:"long_url"`
+		OriginalURL  string     `json:"original_url"`
+		Domain       string     `json:"domain"`
+		TotalClicks  int        `json:"total_clicks"`
+		HealthStatus string     `json:"health_status"`
+		Disabled     bool       `json:"disabled"`
+		CreatedAt    time.Time  `json:"created_at"`
+		ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	}
+	output := struct {
+		Links      []jsonLink `json:"links"`
+		NextCursor string     `json:"next_cursor,omitempty"`
+	}{Links: make([]jsonLink, 0, len(page.Links)), NextCursor: page.NextCursor}
+
+	for _, link := range page.Links {
+		output.Links = append(output.Links, jsonLink{
+			ShortCode:    link.ShortCode,
+			Workspace:    link.Workspace,
+			ShortPath:    link.Path(),
+			LongURL:      link.LongURL,
+			OriginalURL:  link.DisplayURL(),
+			Domain:       link.Domain,
+			TotalClicks:  link.ClickCount,
+			HealthStatus: link.HealthStatus,
+			Disabled:     link.Disabled,
+			CreatedAt:    link.CreatedAt,
+			ExpiresAt:    link.ExpiresAt,
+		})
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(output); err != nil {
+		log.Fatalf("Failed to encode links as JSON: %v", err)
+	}
+}
+
+// printLinksCSV writes a page of links as CSV on stdout
+// The next-page cursor goes to stderr so the CSV stays machine-readable
+func printLinksCSV(page *services.LinkPage) {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"short_code", "long_url", "domain", "total_clicks", "health_status", "disabled", "created_at", "workspace", "original_url"})
+	for _, link := range page.Links {
+		w.Write([]string{
+			link.ShortCode,
+			link.LongURL,
+			link.Domain,
+			strconv.Itoa(link.ClickCount),
+			link.HealthStatus,
+			strconv.FormatBool(link.Disabled),
+			link.CreatedAt.Format(time.RFC3339),
+			link.Workspace,
+			link.DisplayURL(),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		log.Fatalf("Failed to write CSV: %v", err)
+	}
+
+	if page.NextCursor != "" {
+		fmt.Fprintf(os.Stderr, "next_cursor=%s\n", page.NextCursor)
+	}
+}
+
+// linkStatus summarizes whether a link currently redirects
+func linkStatus(link repository.LinkWithClicks) string {
+	if link.Disabled {
+		return "disabled"
+	}
+	if link.IsExpired(time.Now(), link.ClickCount) {
+		return "expired"
+	}
+	return "active"
+}
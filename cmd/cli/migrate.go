@@ -6,10 +6,8 @@ import (
 
 	"github.com/axellelanca/urlshortener/cmd"
 	"github.com/axellelanca/urlshortener/internal/config"
-	"github.com/axellelanca/urlshortener/internal/models"
-	"github.com/glebarez/sqlite"
+	"github.com/axellelanca/urlshortener/internal/database"
 	"github.com/spf13/cobra"
-	"gorm.io/gorm"
 )
 
 // MigrateCmd represents the 'migrate' command
@@ -18,7 +16,7 @@ var MigrateCmd = &cobra.Command{
 	Use:   "migrate",
 	Short: "Executes database migrations to create or update tables.",
 	Long: `This command connects to the configured database (SQLite)
-and executes GORM automatic migrations to create 'links' and 'clicks' tables
+and executes GORM automatic migrations to create 'workspaces', 'links', 'clicks' and 'api_keys' tables
 based on the Go models.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Load configuration to get database connection settings
@@ -28,9 +26,8 @@ based on the Go models.`,
 			log.Fatalf("Failed to load configuration: %v", err)
 		}
 
-		// Initialize database connection using GORM with SQLite driver
-		// Uses the database name specified in the configuration
-		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+		// Open the configured database (SQLite or PostgreSQL) through the shared connection factory
+		db, err := database.Open(cfg)
 		if err != nil {
 			log.Fatalf("Failed to connect to database: %v", err)
 		}
@@ -46,8 +43,8 @@ based on the Go models.`,
 		// Execute GORM automatic migrations
 		// This creates tables based on the struct definitions in our models
 		// It also handles adding new columns if the models have been updated
-		if err := db.AutoMigrate(&models.Link{}, &models.Click{}); err != nil {
-			log.Fatalf("Failed to migrate database: %v", err)
+		if err := database.AutoMigrate(db); err != nil {
+			log.Fatalf("%v", err)
 		}
 
 		// Inform the user that migration completed successfully
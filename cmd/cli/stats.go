@@ -1,39 +1,68 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/axellelanca/urlshortener/cmd"
 	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/database"
+	customerrors "github.com/axellelanca/urlshortener/internal/errors"
+	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
-	"github.com/glebarez/sqlite"
 	"github.com/spf13/cobra"
-	"gorm.io/gorm"
 )
 
 // shortCodeFlag stores the short code provided by the user via the --code flag
 var shortCodeFlag string
 
+// statsWorkspaceFlag stores the slug of the workspace owning the link, provided via the --workspace flag
+var statsWorkspaceFlag string
+
+// Flags controlling the optional time-series section of the stats command
+var (
+	timeSeriesFlag string // Granularity (hour, day or week); empty disables the section
+	fromFlag       string // Start of the range (YYYY-MM-DD or RFC 3339)
+	toFlag         string // End of the range (YYYY-MM-DD or RFC 3339)
+)
+
+// sparkTicks are the block characters used to draw the sparkline, from lowest to highest
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
 // StatsCmd represents the 'stats' command
 // This command allows users to view click statistics for a specific short URL
 var StatsCmd = &cobra.Command{
 	Use:   "stats",
 	Short: "Get statistics for a short URL",
-	Long:  `Get click statistics for the provided short code.`,
-	Run:   runStats, // Delegate to separate function for better organization
+	Long: `Get click statistics for the provided short code.
+
+Examples:
+  url-shortener stats --code="xyz123"
+  url-shortener stats --code="xyz123" --timeseries=day
+  url-shortener stats --code="launch" --workspace=acme
+  url-shortener stats --code="xyz123" --timeseries=hour --from=2025-01-01 --to=2025-01-02`,
+	Run: runStats, // Delegate to separate function for better organization
 }
 
 func init() {
 	// Define the --code flag for the stats command
 	// This flag accepts the short code that the user wants statistics for
 	StatsCmd.Flags().StringVar(&shortCodeFlag, "code", "", "The short code to get statistics for")
+	StatsCmd.Flags().StringVar(&statsWorkspaceFlag, "workspace", models.DefaultWorkspace, "Slug of the workspace owning the link")
 
 	// Mark the flag as required - Cobra will enforce this validation
 	StatsCmd.MarkFlagRequired("code")
 
+	// Define the optional time-series flags
+	StatsCmd.Flags().StringVar(&timeSeriesFlag, "timeseries", "", "Also print clicks over time with this granularity: hour, day or week")
+	StatsCmd.Flags().StringVar(&fromFlag, "from", "", "Start of the time series (YYYY-MM-DD or RFC 3339)")
+	StatsCmd.Flags().StringVar(&toFlag, "to", "", "End of the time series, exclusive (YYYY-MM-DD or RFC 3339)")
+
 	// Register this command with the root command
 	cmd.RootCmd.AddCommand(StatsCmd)
 }
@@ -54,8 +83,8 @@ func runStats(cmd *cobra.Command, args []string) {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Initialize database connection using GORM with SQLite
-	db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
+	// Open the configured database (SQLite or PostgreSQL) through the shared connection factory
+	db, err := database.Open(cfg)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
@@ -70,15 +99,17 @@ func runStats(cmd *cobra.Command, args []string) {
 	// Initialize repository and service layers
 	// Repository handles database operations, service handles business logic
 	linkRepo := repository.NewLinkRepository(db)
-	linkService := services.NewLinkService(linkRepo)
+	clickRepo := repository.NewClickRepository(db)
+	linkService := services.NewLinkService(linkRepo, repository.NewWorkspaceRepository(db), nil, nil)
+	clickService := services.NewClickService(clickRepo)
 
 	// Call GetLinkStats to retrieve the link and its statistics
 	// This includes the link details and total click count
-	link, totalClicks, err := linkService.GetLinkStats(shortCodeFlag)
+	link, totalClicks, err := linkService.GetLinkStats(statsWorkspaceFlag, shortCodeFlag)
 	if err != nil {
 		// Handle the case where the short code doesn't exist
-		if err == gorm.ErrRecordNotFound {
-			fmt.Printf("Error: Short code '%s' not found\n", shortCodeFlag)
+		if errors.Is(err, customerrors.ErrShortCodeNotFound) {
+			fmt.Printf("Error: Short code '%s' not found in workspace '%s'\n", shortCodeFlag, statsWorkspaceFlag)
 		} else {
 			// Handle other database or service errors
 			fmt.Printf("Error retrieving statistics: %v\n", err)
@@ -89,6 +120,150 @@ func runStats(cmd *cobra.Command, args []string) {
 	// Display the results in a user-friendly format
 	fmt.Printf("Statistics for short code: %s\n", shortCodeFlag)
 	fmt.Printf("Long URL: %s\n", link.LongURL)
+	if link.DisplayURL() != link.LongURL {
+		fmt.Printf("Original URL: %s\n", link.DisplayURL())
+	}
 	fmt.Printf("Total clicks: %d\n", totalClicks)
+
+	// Display unique visitors right next to the raw click count
+	uniqueVisitors, err := clickService.GetUniqueVisitors(link.ID)
+	if err != nil {
+		fmt.Printf("Error counting unique visitors: %v\n", err)
+		os.Exit(1)
+	}
+	fmt.Printf("Unique visitors: %d\n", uniqueVisitors)
 	fmt.Printf("Creation date: %s\n", link.CreatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Printf("Redirect status: %d\n", link.EffectiveRedirectStatus(cfg.Server.RedirectStatus))
+
+	// Display expiration details only for links that have them
+	now := time.Now()
+	if remaining := link.RemainingLifetime(now); remaining != nil {
+		fmt.Printf("Expires at: %s (remaining: %s)\n", link.ExpiresAt.Format("2006-01-02 15:04:05"), remaining.Round(time.Second))
+	}
+	if remaining := link.RemainingClicks(totalClicks); remaining != nil {
+		fmt.Printf("Remaining clicks: %d of %d\n", *remaining, link.MaxClicks)
+	}
+	if link.Disabled {
+		fmt.Println("Status: DISABLED")
+	} else if link.IsExpired(now, totalClicks) {
+		fmt.Println("Status: EXPIRED")
+	}
+
+	// Display who clicked, with bots kept apart from human traffic
+	userAgents, err := clickService.GetUserAgentBreakdown(link.ID)
+	if err != nil {
+		fmt.Printf("Error retrieving user agent breakdown: %v\n", err)
+		os.Exit(1)
+	}
+	printUserAgentBreakdown(userAgents)
+
+	// Display clicks per country (only meaningful when the server geolocates clicks)
+	countries, err := clickService.GetCountryBreakdown(link.ID)
+	if err != nil {
+		fmt.Printf("Error retrieving country breakdown: %v\n", err)
+		os.Exit(1)
+	}
+	printGroupCounts("Countries", countries)
+
+	// Display the sites sending the most traffic
+	referrers, err := clickService.GetTopReferrers(link.ID)
+	if err != nil {
+		fmt.Printf("Error retrieving referrers: %v\n", err)
+		os.Exit(1)
+	}
+	printGroupCounts("Top referrers", referrers)
+
+	// Display the optional time series
+	if timeSeriesFlag != "" {
+		printTimeSeries(clickService, link.ID)
+	}
+}
+
+// printUserAgentBreakdown prints the human/bot split and the per-family counts
+func printUserAgentBreakdown(breakdown *services.UserAgentBreakdown) {
+	fmt.Printf("Human clicks: %d\n", breakdown.HumanClicks)
+	fmt.Printf("Bot clicks: %d\n", breakdown.BotClicks)
+
+	printGroupCounts("Browsers", breakdown.Browsers)
+	printGroupCounts("Operating systems", breakdown.OperatingSystems)
+	printGroupCounts("Devices", breakdown.Devices)
+	printGroupCounts("Bots", breakdown.Bots)
+	printGroupCounts("Top user agents", breakdown.TopUserAgents)
+}
+
+// printGroupCounts prints a titled list of value/count pairs, skipping empty lists
+func printGroupCounts(title string, counts []repository.GroupCount) {
+	if len(counts) == 0 {
+		return
+	}
+	fmt.Printf("\n%s:\n", title)
+	for _, entry := range counts {
+		value := entry.Value
+		if value == "" {
+			value = "(none)"
+		}
+		fmt.Printf("  %-6d %s\n", entry.Count, value)
+	}
+}
+
+// printTimeSeries prints the clicks of a link bucketed over time, followed by a sparkline
+func printTimeSeries(clickService *services.ClickService, linkID uint) {
+	from, err := services.ParseOptionalDate(fromFlag)
+	if err != nil {
+		fmt.Printf("Error: Invalid --from value '%s': %v\n", fromFlag, err)
+		os.Exit(1)
+	}
+	to, err := services.ParseOptionalDate(toFlag)
+	if err != nil {
+		fmt.Printf("Error: Invalid --to value '%s': %v\n", toFlag, err)
+		os.Exit(1)
+	}
+
+	var fromTime, toTime time.Time
+	if from != nil {
+		fromTime = *from
+	}
+	if to != nil {
+		toTime = *to
+	}
+	series, err := clickService.GetClickTimeSeries(linkID, timeSeriesFlag, fromTime, toTime)
+	if err != nil {
+		fmt.Printf("Error retrieving time series: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Pick a label format matching the granularity
+	layout := "2006-01-02"
+	if timeSeriesFlag == repository.GranularityHour {
+		layout = "2006-01-02 15:04"
+	}
+
+	fmt.Printf("\nClicks per %s (UTC):\n", timeSeriesFlag)
+	counts := make([]int, 0, len(series))
+	for _, point := range series {
+		fmt.Printf("  %s  %d\n", point.Start.Format(layout), point.Count)
+		counts = append(counts, point.Count)
+	}
+	fmt.Printf("\n  %s\n", sparkline(counts))
+}
+
+// sparkline renders a series of counts as a single line of block characters
+// The tallest block always corresponds to the maximum count of the series
+func sparkline(counts []int) string {
+	maxCount := 0
+	for _, count := range counts {
+		if count > maxCount {
+			maxCount = count
+		}
+	}
+
+	var sb strings.Builder
+	for _, count := range counts {
+		index := 0
+		if maxCount > 0 {
+			index = count * (len(sparkTicks) - 1) / maxCount
+		}
+		sb.WriteRune(sparkTicks[index])
+	}
+	return sb.String()
 }
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+
+	"github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/database"
+	customerrors "github.com/axellelanca/urlshortener/internal/errors"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+)
+
+// updateCodeFlag stores the short code of the link to update, provided via the --code flag
+var updateCodeFlag string
+
+// updateURLFlag stores the new destination URL, provided via the --url flag
+var updateURLFlag string
+
+// updateWorkspaceFlag stores the slug of the workspace owning the link, provided via the --workspace flag
+var updateWorkspaceFlag string
+
+// UpdateCmd represents the 'update' command
+// This command changes the long URL a short code points to, without changing the short code
+var UpdateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Changes the destination URL of an existing short URL.",
+	Long: `This command points an existing short code to a new long URL.
+The short code and its click history are kept unchanged.
+
+Example:
+  url-shortener update --code="spring-sale" --url="https://www.example.com/spring-sale-2"`,
+	Run: runUpdate,
+}
+
+func init() {
+	// Define the required --code and --url flags for the update command
+	UpdateCmd.Flags().StringVar(&updateCodeFlag, "code", "", "The short code of the link to update")
+	UpdateCmd.Flags().StringVar(&updateURLFlag, "url", "", "The new long URL")
+	UpdateCmd.MarkFlagRequired("code")
+	UpdateCmd.MarkFlagRequired("url")
+	UpdateCmd.Flags().StringVar(&updateWorkspaceFlag, "workspace", models.DefaultWorkspace, "Slug of the workspace owning the link")
+
+	// Register this command with the root command
+	cmd.RootCmd.AddCommand(UpdateCmd)
+}
+
+// runUpdate executes the logic for the update command
+func runUpdate(_ *cobra.Command, args []string) {
+	// Validate the new URL before touching the database
+	if _, err := url.ParseRequestURI(updateURLFlag); err != nil {
+		fmt.Printf("Error: Invalid URL format (%s): %v\n", updateURLFlag, err)
+		os.Exit(1)
+	}
+
+	// Load application configuration to get database settings
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// The new destination goes through the same policy as the API
+	policy, err := cmd.NewDestinationPolicy(cfg)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+
+	// Open the configured database (SQLite or PostgreSQL) through the shared connection factory
+	db, err := database.Open(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	// Get underlying SQL connection for proper cleanup
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("FATAL: Failed to get underlying SQL database: %v", err)
+	}
+	defer sqlDB.Close() // Ensure database connection is closed
+
+	// Initialize repository and service layers
+	linkRepo := repository.NewLinkRepository(db)
+	linkService := services.NewLinkService(linkRepo, repository.NewWorkspaceRepository(db), policy, cmd.NewURLNormalizer(cfg))
+
+	link, err := linkService.UpdateLongURL(context.Background(), updateWorkspaceFlag, updateCodeFlag, updateURLFlag)
+	if err != nil {
+		if errors.Is(err, customerrors.ErrShortCodeNotFound) {
+			fmt.Printf("Error: Short code '%s' not found\n", updateCodeFlag)
+		} else {
+			fmt.Printf("Error updating link: %v\n", err)
+		}
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ Short code '%s' now redirects to %s\n", link.ShortCode, link.LongURL)
+}
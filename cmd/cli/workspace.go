@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/axellelanca/urlshortener/cmd"
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/spf13/cobra"
+)
+
+// workspaceSlugFlag stores the slug of the workspace to create via the --slug flag
+var workspaceSlugFlag string
+
+// workspaceNameFlag stores the label of the workspace to create via the --name flag
+var workspaceNameFlag string
+
+// workspaceNamespacedFlag gives the workspace to create its own short code namespace via the --namespaced flag
+var workspaceNamespacedFlag bool
+
+// WorkspaceCmd groups the commands managing workspaces
+var WorkspaceCmd = &cobra.Command{
+	Use:   "workspace",
+	Short: "Manages the workspaces owning links and API keys.",
+}
+
+// WorkspaceCreateCmd represents the 'workspace create' command
+var WorkspaceCreateCmd = &cobra.Command{
+	Use:   "create",
+	Short: "Creates a new workspace.",
+	Long: `This command creates a workspace. Links and API keys belong to exactly one workspace,
+and an API key only sees the links of its own workspace.
+
+By default the short codes of a workspace share the global namespace and redirect from
+/<code>. With --namespaced, the workspace gets its own namespace: its codes only need to
+be unique within the workspace and redirect from /<slug>/<code>. This choice cannot be
+changed later, since it would change the URL of every existing link.
+
+Examples:
+  url-shortener workspace create --slug=marketing --name="Marketing team"
+  url-shortener workspace create --slug=acme --name="ACME Corp" --namespaced`,
+	Run: runWorkspaceCreate,
+}
+
+// WorkspaceListCmd represents the 'workspace list' command
+var WorkspaceListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the workspaces.",
+	Run:   runWorkspaceList,
+}
+
+func init() {
+	WorkspaceCreateCmd.Flags().StringVar(&workspaceSlugFlag, "slug", "", "Unique identifier of the workspace (3-32 letters, digits, '-' or '_')")
+	WorkspaceCreateCmd.Flags().StringVar(&workspaceNameFlag, "name", "", "Human-readable name of the workspace (default: the slug)")
+	WorkspaceCreateCmd.Flags().BoolVar(&workspaceNamespacedFlag, "namespaced", false, "Give the workspace its own short code namespace (/<slug>/<code>)")
+	WorkspaceCreateCmd.MarkFlagRequired("slug")
+
+	// Register the subcommands under 'workspace', then 'workspace' with the root command
+	WorkspaceCmd.AddCommand(WorkspaceCreateCmd, WorkspaceListCmd)
+	cmd.RootCmd.AddCommand(WorkspaceCmd)
+}
+
+// runWorkspaceCreate executes the logic for the 'workspace create' command
+func runWorkspaceCreate(cmd *cobra.Command, args []string) {
+	workspaceService, sqlDB := openWorkspaceService()
+	defer sqlDB.Close()
+
+	workspace, err := workspaceService.CreateWorkspace(workspaceSlugFlag, workspaceNameFlag, workspaceNamespacedFlag)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✅ Workspace '%s' created (ID %d)\n", workspace.Slug, workspace.ID)
+	if workspace.NamespacedCodes {
+		fmt.Printf("   Its links redirect from /%s/<code>\n", workspace.Slug)
+	}
+	fmt.Printf("   Create an API key for it with: apikey create --workspace=%s --name=... --scopes=...\n", workspace.Slug)
+}
+
+// runWorkspaceList executes the logic for the 'workspace list' command
+func runWorkspaceList(cmd *cobra.Command, args []string) {
+	workspaceService, sqlDB := openWorkspaceService()
+	defer sqlDB.Close()
+
+	workspaces, err := workspaceService.ListWorkspaces()
+	if err != nil {
+		fmt.Printf("Error listing workspaces: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("%-5s %-32s %-30s %-11s %s\n", "ID", "SLUG", "NAME", "NAMESPACED", "CREATED")
+	for _, workspace := range workspaces {
+		namespaced := "no"
+		if workspace.NamespacedCodes {
+			namespaced = "yes"
+		}
+		fmt.Printf("%-5d %-32s %-30s %-11s %s\n", workspace.ID, workspace.Slug, workspace.Name,
+			namespaced, workspace.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+}
+
+// openWorkspaceService connects to the configured database and builds the workspace service
+// shared by the workspace subcommands. The caller must close the returned connection.
+func openWorkspaceService() (*services.WorkspaceService, *sql.DB) {
+	// Load application configuration to get database settings
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		log.Fatalf("Failed to load configuration: %v", err)
+	}
+
+	// Open the configured database (SQLite or PostgreSQL) through the shared connection factory
+	db, err := database.Open(cfg)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	// Get underlying SQL connection for proper cleanup
+	sqlDB, err := db.DB()
+	if err != nil {
+		log.Fatalf("FATAL: Failed to get underlying SQL database: %v", err)
+	}
+
+	// The workspaces table may not exist yet on a database created before workspaces
+	if err := database.AutoMigrate(db); err != nil {
+		log.Fatalf("Failed to migrate the database: %v", err)
+	}
+
+	return services.NewWorkspaceService(repository.NewWorkspaceRepository(db)), sqlDB
+}
@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/axellelanca/urlshortener/internal/urlnorm"
+)
+
+// NewDestinationPolicy builds the destination policy described by the destination configuration.
+// The server and the CLI commands that set a link destination (create, update) share it,
+// so a URL refused by the API cannot be shortened from the command line either.
+// Parameters:
+//   - cfg: the loaded configuration
+//
+// Returns:
+//   - *services.DestinationPolicy: the policy to pass to services.NewLinkService
+//   - error: if the configuration is invalid or a list file cannot be read
+func NewDestinationPolicy(cfg *config.Config) (*services.DestinationPolicy, error) {
+	policy, err := services.NewDestinationPolicy(services.DestinationPolicyOptions{
+		AllowedSchemes:       cfg.Destination.AllowedSchemes,
+		BlocklistPath:        cfg.Destination.BlocklistPath,
+		AllowlistPath:        cfg.Destination.AllowlistPath,
+		ReloadInterval:       time.Duration(cfg.Destination.ReloadIntervalSeconds) * time.Second,
+		BlockPrivateNetworks: cfg.Destination.BlockPrivateNetworks,
+		ResolveHosts:         cfg.Destination.ResolveHosts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid destination policy: %w", err)
+	}
+	return policy, nil
+}
+
+// NewURLNormalizer builds the long URL normalizer described by the normalization configuration.
+// Like the destination policy, it is shared by the server and the create/update commands.
+// Parameters:
+//   - cfg: the loaded configuration
+//
+// Returns:
+//   - *urlnorm.Normalizer: the normalizer to pass to services.NewLinkService, nil when normalization is disabled
+func NewURLNormalizer(cfg *config.Config) *urlnorm.Normalizer {
+	if !cfg.Normalization.Enabled {
+		return nil
+	}
+	return urlnorm.New(urlnorm.Options{
+		LowercaseHost:       cfg.Normalization.LowercaseHost,
+		StripDefaultPort:    cfg.Normalization.StripDefaultPort,
+		CleanPath:           cfg.Normalization.CleanPath,
+		IDNToASCII:          cfg.Normalization.IDNToASCII,
+		StripTrackingParams: cfg.Normalization.StripTrackingParams,
+		TrackingParams:      cfg.Normalization.TrackingParams,
+	})
+}
@@ -2,10 +2,11 @@ package cmd
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"os"
 
 	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/logging"
 	"github.com/spf13/cobra"
 )
 
@@ -56,7 +57,18 @@ func initConfig() {
 	if err != nil {
 		// Log warning but don't exit if LoadConfig() handles missing files gracefully
 		// If LoadConfig() terminates the program on fatal errors, this check is mainly for warnings
-		log.Printf("Warning: Problem loading configuration: %v. Using default values.", err)
+		slog.Warn("Problem loading configuration, using default values", "error", err)
+	}
+
+	// Install the structured logger (level and format from logging.*) before any command logs
+	// An invalid setting falls back to info-level text logs rather than preventing the command from running
+	level, format := "info", logging.FormatText
+	if Cfg != nil {
+		level, format = Cfg.Logging.Level, Cfg.Logging.Format
+	}
+	if _, err := logging.Setup(level, format, os.Stderr); err != nil {
+		logging.Setup("info", logging.FormatText, os.Stderr)
+		slog.Warn("Invalid logging configuration, using info-level text logs", "error", err)
 	}
 
 	// Configuration is now available via the global variable 'cmd.Cfg'
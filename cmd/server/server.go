@@ -1,8 +1,12 @@
 package server
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"log"
+	"log/slog"
 	"net/http"
 	"os"
 	"os/signal"
@@ -12,17 +16,21 @@ import (
 	"github.com/axellelanca/urlshortener/cmd"
 	"github.com/axellelanca/urlshortener/internal/api"
 	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/metrics"
 	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/monitor"
+	"github.com/axellelanca/urlshortener/internal/ratelimit"
 	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
 	"github.com/axellelanca/urlshortener/internal/workers"
 	"github.com/gin-gonic/gin"
-	"github.com/glebarez/sqlite"
 	"github.com/spf13/cobra"
-	"gorm.io/gorm"
 )
 
+// ephemeralFlag stores whether the server should use in-memory storage instead of the database (--ephemeral)
+var ephemeralFlag bool
+
 // RunServerCmd represents the 'run-server' Cobra command
 // This is the entry point for launching the application server
 var RunServerCmd = &cobra.Command{
@@ -30,8 +38,11 @@ var RunServerCmd = &cobra.Command{
 	Short: "Launches the URL shortening API server and background processes.",
 	Long: `This command initializes the database, configures the APIs,
 starts asynchronous workers for click tracking and URL monitoring,
-then launches the HTTP server.`,
-	Run: func(cmd *cobra.Command, args []string) {
+then launches the HTTP server.
+
+With --ephemeral, links and clicks are kept in memory instead of the configured
+database, which is handy for demos and integration tests; all data is lost on shutdown.`,
+	Run: func(_ *cobra.Command, args []string) {
 		// Load application configuration from files or environment variables
 		// This contains all settings for database, server, analytics, and monitoring
 		cfg, err := config.LoadConfig()
@@ -39,61 +50,195 @@ then launches the HTTP server.`,
 			log.Fatalf("Failed to load configuration: %v", err)
 		}
 
-		// Initialize database connection using GORM with SQLite
-		// GORM provides an ORM layer over the raw database operations
-		db, err := gorm.Open(sqlite.Open(cfg.Database.Name), &gorm.Config{})
-		if err != nil {
-			log.Fatalf("Failed to connect to database: %v", err)
-		}
-
-		// Automatic migration of database models to create/update tables
-		// This ensures the database schema matches our Go structs
-		if err := db.AutoMigrate(&models.Link{}, &models.Click{}); err != nil {
-			log.Fatalf("Failed to migrate database: %v", err)
+		// Refuse to start with a default redirect status the handlers cannot issue
+		if err := services.ValidateRedirectStatus(cfg.Server.RedirectStatus); err != nil {
+			log.Fatalf("Invalid server.redirect_status: %v", err)
 		}
 
 		// Initialize repository layer for data access
 		// Repositories abstract database operations behind interfaces
-		linkRepo := repository.NewLinkRepository(db)
-		clickRepo := repository.NewClickRepository(db)
+		var linkRepo repository.LinkRepository
+		var clickRepo repository.ClickRepository
+		var apiKeyRepo repository.APIKeyRepository
+		var workspaceRepo repository.WorkspaceRepository
+		if ephemeralFlag {
+			// Ephemeral mode: keep everything in memory, nothing touches the disk or a database server
+			store := repository.NewMemoryStore()
+			linkRepo = repository.NewMemoryLinkRepository(store)
+			clickRepo = repository.NewMemoryClickRepository(store)
+			apiKeyRepo = repository.NewMemoryAPIKeyRepository(store)
+			workspaceRepo = repository.NewMemoryWorkspaceRepository(store)
+			slog.Warn("Ephemeral mode enabled, links and clicks are kept in memory and lost on shutdown")
+		} else {
+			// Open the configured database (SQLite or PostgreSQL) through the shared connection factory
+			db, err := database.Open(cfg)
+			if err != nil {
+				log.Fatalf("Failed to connect to database: %v", err)
+			}
+
+			// Automatic migration of database models to create/update tables
+			// This ensures the database schema matches our Go structs
+			if err := database.AutoMigrate(db); err != nil {
+				log.Fatalf("%v", err)
+			}
+
+			linkRepo = repository.NewLinkRepository(db)
+			clickRepo = repository.NewClickRepository(db)
+			apiKeyRepo = repository.NewAPIKeyRepository(db)
+			workspaceRepo = repository.NewWorkspaceRepository(db)
+		}
+
+		// Put the short-code cache in front of the link repository so redirects rarely reach the database
+		if cfg.Cache.Enabled {
+			linkRepo = repository.NewCachedLinkRepository(linkRepo, repository.LinkCacheOptions{
+				Size:        cfg.Cache.Size,
+				TTL:         time.Duration(cfg.Cache.TTLSeconds) * time.Second,
+				NegativeTTL: time.Duration(cfg.Cache.NegativeTTLSeconds) * time.Second,
+			})
+			slog.Info("Short-code cache enabled",
+				"size", cfg.Cache.Size, "ttl_seconds", cfg.Cache.TTLSeconds, "negative_ttl_seconds", cfg.Cache.NegativeTTLSeconds)
+		}
 
 		// Log successful repository initialization for debugging
-		log.Println("Repositories initialized.")
+		slog.Info("Repositories initialized")
 
 		// Initialize business logic services
 		// Services contain the core business logic of the application
-		linkService := services.NewLinkService(linkRepo)
+		destinationPolicy, err := cmd.NewDestinationPolicy(cfg)
+		if err != nil {
+			log.Fatalf("FATAL: %v", err)
+		}
+		linkService := services.NewLinkService(linkRepo, workspaceRepo, destinationPolicy, cmd.NewURLNormalizer(cfg))
+		clickService := services.NewClickService(clickRepo)
+		apiKeyService := services.NewAPIKeyService(apiKeyRepo, workspaceRepo)
 
 		// Log successful service initialization for debugging
-		log.Println("Business services initialized.")
+		slog.Info("Business services initialized")
+
+		// In ephemeral mode the 'apikey' commands cannot reach the in-memory store,
+		// so an admin key is generated for this run and printed once
+		if cfg.Auth.Enabled && ephemeralFlag {
+			plainKey, key, err := apiKeyService.CreateAPIKey("ephemeral-admin", models.DefaultWorkspace, []string{models.ScopeAdmin})
+			if err != nil {
+				log.Fatalf("Failed to create the ephemeral admin API key: %v", err)
+			}
+			// The key goes to the terminal only: structured logs may be shipped to a collector,
+			// so they just carry the prefix that identifies it
+			fmt.Printf("Ephemeral admin API key for this run: %s\n", plainKey)
+			slog.Warn("Ephemeral admin API key generated for this run and printed to stdout", "api_key_prefix", key.Prefix)
+		} else if !cfg.Auth.Enabled {
+			slog.Warn("API key authentication disabled (auth.enabled=false), /api/v1 is open to anyone")
+		}
 
 		// Initialize click events channel for asynchronous click processing
 		// This channel decouples URL redirection from click recording for better performance
 		clickEventsChan := make(chan models.ClickEvent, cfg.Analytics.BufferSize)
 		api.ClickEventsChannel = clickEventsChan // Set the global channel used by handlers
 
+		// Open the optional offline GeoIP database used by workers to geolocate clicks
+		// A missing or invalid file only disables geolocation, the server still starts
+		// The file is closed at the end of the shutdown, once no worker can call Lookup anymore
+		var geoLocator services.GeoLocator
+		var maxMindLocator *services.MaxMindGeoLocator
+		if cfg.GeoIP.DatabasePath != "" {
+			locator, err := services.NewMaxMindGeoLocator(cfg.GeoIP.DatabasePath)
+			if err != nil {
+				slog.Warn("Click geolocation disabled", "error", err)
+			} else {
+				geoLocator = locator
+				maxMindLocator = locator
+				slog.Info("Click geolocation enabled", "database_path", cfg.GeoIP.DatabasePath)
+			}
+		}
+
+		// Use the configured visitor salt, or a random one for this run
+		// With a random salt, a visitor seen before and after a restart on the same day is counted twice
+		visitorSalt := cfg.Analytics.VisitorSalt
+		if visitorSalt == "" {
+			salt := make([]byte, 32)
+			if _, err := rand.Read(salt); err != nil {
+				log.Fatalf("Failed to generate visitor salt: %v", err)
+			}
+			visitorSalt = hex.EncodeToString(salt)
+			slog.Warn("analytics.visitor_salt is not set, using a random salt for this run")
+		}
+
+		// Clicks that still cannot be written after retries go to the dead-letter file
+		// Ephemeral mode never writes to disk, so they are only logged there
+		var deadLetters *workers.DeadLetterStore
+		if cfg.Analytics.DeadLetterPath != "" && !ephemeralFlag {
+			deadLetters = workers.NewDeadLetterStore(cfg.Analytics.DeadLetterPath)
+		}
+
 		// Start worker goroutines to process click events asynchronously
 		// Workers run in background and save click data to database
-		workers.StartClickWorkers(cfg.Analytics.WorkerCount, clickEventsChan, clickRepo)
+		workerPool := workers.StartClickWorkers(cfg.Analytics.WorkerCount, clickEventsChan, clickRepo, workers.WorkerOptions{
+			GeoLocator:    geoLocator,
+			VisitorSalt:   visitorSalt,
+			BatchSize:     cfg.Analytics.BatchSize,
+			FlushInterval: time.Duration(cfg.Analytics.FlushMillis) * time.Millisecond,
+			RetryAttempts: cfg.Analytics.RetryAttempts,
+			RetryBackoff:  time.Duration(cfg.Analytics.RetryBackoffMillis) * time.Millisecond,
+			DeadLetters:   deadLetters,
+		})
 
 		// Log the initialization of click processing system
-		log.Printf("Click events channel initialized with buffer size %d. %d click worker(s) started.",
-			cfg.Analytics.BufferSize, cfg.Analytics.WorkerCount)
+		slog.Info("Click events channel initialized",
+			"buffer_size", cfg.Analytics.BufferSize, "workers", cfg.Analytics.WorkerCount)
+
+		// Background goroutines (spool replayer, URL monitor) run until this context is cancelled on shutdown
+		runCtx, cancelRun := context.WithCancel(context.Background())
+		defer cancelRun()
+
+		// Open the overflow spool receiving click events when the channel is full, and start replaying
+		// it into the workers (this also recovers events spooled before the last shutdown)
+		// Ephemeral mode never writes to disk, so overflow events are only counted as dropped there
+		spoolPath := cfg.Analytics.SpoolPath
+		if ephemeralFlag {
+			spoolPath = ""
+		}
+		clickSpool, err := workers.NewClickSpool(spoolPath)
+		if err != nil {
+			log.Fatalf("Failed to open click spool: %v", err)
+		}
+		defer clickSpool.Close()
+		api.ClickSpool = clickSpool
+		replayerDone := clickSpool.StartReplayer(runCtx, clickEventsChan, time.Duration(cfg.Analytics.SpoolReplaySeconds)*time.Second)
+		if spoolPath != "" {
+			slog.Info("Click overflow spool enabled", "path", spoolPath)
+		}
+
+		// Expose the state held by the click pipeline and the cache, read on each scrape
+		if cfg.Metrics.Enabled {
+			registerPipelineMetrics(clickEventsChan, clickSpool, linkService)
+			slog.Info("Prometheus metrics exposed", "path", cfg.Metrics.Path)
+		}
 
 		// Initialize and start the URL health monitoring system
 		// This periodically checks if shortened URLs are still accessible
 		monitorInterval := time.Duration(cfg.Monitor.IntervalMinutes) * time.Minute
-		urlMonitor := monitor.NewUrlMonitor(linkRepo, monitorInterval)
-		go urlMonitor.Start() // Run monitor in background goroutine
-		log.Printf("URL monitor started with interval of %v.", monitorInterval)
+		urlMonitor := monitor.NewUrlMonitor(linkRepo, destinationPolicy, monitorInterval)
+		monitorDone := make(chan struct{})
+		go func() { // Run monitor in background goroutine
+			defer close(monitorDone)
+			urlMonitor.Start(runCtx)
+		}()
+		slog.Info("URL monitor started", "interval", monitorInterval)
 
 		// Configure Gin router and API handlers
 		// Gin is the HTTP framework used for routing and middleware
-		router := gin.Default()
-		api.SetupRoutes(router, linkService, cfg.Analytics.BufferSize)
+		// Gin's default text logger is replaced by the structured request logger of api.SetupRoutes
+		router := gin.New()
+		router.Use(gin.Recovery())
+		// Only the configured reverse proxies may set the client IP through X-Forwarded-For,
+		// otherwise any client could pick its own IP and escape per-IP rate limits
+		if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+			log.Fatalf("FATAL: Invalid server.trusted_proxies: %v", err)
+		}
+		api.SetupRoutes(router, linkService, clickService, apiKeyService, newRateLimiters(cfg), cfg)
 
 		// Log successful API route configuration
-		log.Println("API routes configured.")
+		slog.Info("API routes configured")
 
 		// Create HTTP server instance with Gin router
 		// This prepares the server but doesn't start it yet
@@ -106,7 +251,7 @@ then launches the HTTP server.`,
 		// Start the HTTP server in a separate goroutine to avoid blocking
 		// This allows the main goroutine to handle shutdown signals
 		go func() {
-			log.Printf("Starting server on %s", serverAddr)
+			slog.Info("Starting server", "addr", serverAddr)
 			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 				log.Fatalf("Failed to start server: %v", err)
 			}
@@ -120,18 +265,135 @@ then launches the HTTP server.`,
 		// Block until a shutdown signal is received
 		// This keeps the main goroutine alive while the server runs
 		<-quit
-		log.Println("Shutdown signal received. Stopping server...")
+		slog.Info("Shutdown signal received, stopping server")
 
-		// Graceful shutdown with timeout for workers to finish
-		// Give background workers time to complete their current tasks
-		log.Println("Shutting down... Giving workers time to finish.")
-		time.Sleep(5 * time.Second)
+		// The HTTP drain and the click flush each get server.shutdown_timeout_seconds, so a slow
+		// drain cannot leave the workers with an already expired deadline
+		shutdownTimeout := time.Duration(cfg.Server.ShutdownTimeoutSeconds) * time.Second
+		httpCtx, cancelHTTP := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancelHTTP()
+
+		// Stop accepting connections and wait for in-flight requests (and their click events) to complete
+		httpErr := srv.Shutdown(httpCtx)
+		if httpErr != nil {
+			slog.Warn("HTTP server did not drain in-flight requests in time", "error", httpErr)
+		} else {
+			slog.Info("HTTP server stopped, in-flight requests drained")
+		}
 
-		log.Println("Server stopped gracefully.")
+		flushCtx, cancelFlush := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancelFlush()
+
+		// Stop the URL monitor ticker and the spool replayer; the replayer must be done
+		// before the click channel is closed since it sends to it
+		cancelRun()
+		for name, done := range map[string]<-chan struct{}{"URL monitor": monitorDone, "click spool replayer": replayerDone} {
+			select {
+			case <-done:
+			case <-flushCtx.Done():
+				slog.Warn("Background task did not stop before the shutdown timeout", "task", name)
+			}
+		}
+
+		// Close the click channel so workers flush their last batch and exit, then wait for them
+		// Events are counted before closing: those still queued plus those held in worker batches
+		pendingEvents := len(clickEventsChan) + workerPool.Pending()
+		persistedBefore := workerPool.Persisted()
+		var waitErr error
+		if httpErr != nil {
+			// Handlers still running could send to a closed channel and panic, so the channel stays
+			// open and the workers are abandoned along with the events held in their batches
+			waitErr = httpErr
+		} else {
+			close(clickEventsChan)
+			slog.Info("Waiting for click workers to flush pending events", "pending", pendingEvents)
+			waitErr = workerPool.Wait(flushCtx)
+		}
+
+		// Events the workers did not take in time go to the spool and are replayed on the next startup
+		spooled := 0
+		if waitErr != nil && clickSpool.Stats().Enabled {
+			spooled = clickSpool.Drain(clickEventsChan)
+		}
+
+		flushed := int(workerPool.Persisted() - persistedBefore)
+		abandoned := pendingEvents - flushed - spooled
+		if abandoned < 0 {
+			abandoned = 0
+		}
+		if waitErr != nil {
+			slog.Warn("Click workers did not finish before the shutdown timeout",
+				"timeout", shutdownTimeout, "flushed", flushed, "spooled", spooled, "abandoned", abandoned)
+		} else {
+			slog.Info("Click workers stopped", "flushed", flushed, "abandoned", abandoned)
+		}
+
+		// Unmapping the GeoIP database under a worker still calling Lookup would crash the process
+		// instead of returning an error, so it is only closed once every worker has exited
+		if maxMindLocator != nil {
+			if waitErr == nil {
+				maxMindLocator.Close()
+			} else {
+				slog.Warn("Click workers still running, leaving the GeoIP database open until exit")
+			}
+		}
+
+		slog.Info("Server stopped gracefully")
 	},
 }
 
 func init() {
+	// Define the --ephemeral flag to run without any persistent storage
+	RunServerCmd.Flags().BoolVar(&ephemeralFlag, "ephemeral", false, "Keep links and clicks in memory only (nothing is written to disk)")
+
 	// Register this command with the root command so it can be executed
 	cmd.RootCmd.AddCommand(RunServerCmd)
 }
+
+// registerPipelineMetrics exposes the click channel depth, the spool counters and the short-code
+// cache counters as metrics computed at scrape time from the components that already track them.
+func registerPipelineMetrics(clickEventsChan chan models.ClickEvent, clickSpool *workers.ClickSpool, linkService *services.LinkService) {
+	metrics.RegisterGaugeFunc("click_channel_depth", "Number of click events waiting in the channel.",
+		func() float64 { return float64(len(clickEventsChan)) })
+	metrics.RegisterGaugeFunc("click_channel_capacity", "Capacity of the click event channel.",
+		func() float64 { return float64(cap(clickEventsChan)) })
+	metrics.RegisterCounterFunc("clicks_spooled_total", "Click events written to the overflow spool because the channel was full.",
+		func() float64 { return float64(clickSpool.Stats().Spooled) })
+	metrics.RegisterCounterFunc("clicks_replayed_total", "Spooled click events fed back into the channel.",
+		func() float64 { return float64(clickSpool.Stats().Replayed) })
+	metrics.RegisterCounterFunc("clicks_dropped_total", "Click events lost before reaching a worker.",
+		func() float64 { return float64(clickSpool.Stats().Dropped) })
+
+	if _, ok := linkService.CacheStats(); !ok {
+		return
+	}
+	metrics.RegisterCounterFunc("link_cache_hits_total", "Short-code lookups answered by the cache.",
+		func() float64 { stats, _ := linkService.CacheStats(); return float64(stats.Hits) })
+	metrics.RegisterCounterFunc("link_cache_misses_total", "Short-code lookups that reached the repository.",
+		func() float64 { stats, _ := linkService.CacheStats(); return float64(stats.Misses) })
+	metrics.RegisterCounterFunc("link_cache_evictions_total", "Short codes evicted from the cache to make room.",
+		func() float64 { stats, _ := linkService.CacheStats(); return float64(stats.Evictions) })
+}
+
+// newRateLimiters builds the in-process token bucket limiters described by the rate_limit configuration.
+// Rules with no rate, or all of them when rate limiting is disabled, are left nil so their routes are not limited.
+func newRateLimiters(cfg *config.Config) api.RateLimiters {
+	var limiters api.RateLimiters
+	if !cfg.RateLimit.Enabled {
+		slog.Warn("Rate limiting is disabled (rate_limit.enabled=false)")
+		return limiters
+	}
+
+	create := ratelimit.Rule{RequestsPerMinute: cfg.RateLimit.Create.RequestsPerMinute, Burst: cfg.RateLimit.Create.Burst}
+	if create.Enabled() {
+		limiters.Create = ratelimit.NewMemoryLimiter(create)
+	}
+	redirect := ratelimit.Rule{RequestsPerMinute: cfg.RateLimit.Redirect.RequestsPerMinute, Burst: cfg.RateLimit.Redirect.Burst}
+	if redirect.Enabled() {
+		limiters.Redirect = ratelimit.NewMemoryLimiter(redirect)
+	}
+	slog.Info("Rate limiting enabled",
+		"create_per_minute", create.RequestsPerMinute, "create_burst", create.Burst,
+		"redirect_per_minute", redirect.RequestsPerMinute, "redirect_burst", redirect.Burst)
+	return limiters
+}
@@ -2,13 +2,21 @@ package api
 
 import (
 	"errors"
-	"log"
+	"fmt"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/axellelanca/urlshortener/internal/config"
 	customerrors "github.com/axellelanca/urlshortener/internal/errors"
+	"github.com/axellelanca/urlshortener/internal/logging"
+	"github.com/axellelanca/urlshortener/internal/metrics"
 	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/ratelimit"
+	"github.com/axellelanca/urlshortener/internal/repository"
 	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/axellelanca/urlshortener/internal/urlnorm"
+	"github.com/axellelanca/urlshortener/internal/workers"
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,60 +24,121 @@ import (
 // This channel enables asynchronous processing of click analytics without blocking URL redirection
 var ClickEventsChannel chan models.ClickEvent
 
+// ClickSpool is the global overflow spool receiving click events when ClickEventsChannel is full
+// When nil, overflow events are dropped as before
+var ClickSpool *workers.ClickSpool
+
 // SetupRoutes configures all Gin API routes and injects necessary dependencies
 // This function is the main routing configuration that sets up all HTTP endpoints
 // Parameters:
 //   - router: Gin engine instance to configure routes on
 //   - linkService: business logic service for link operations
-//   - bufferSize: size of the click events channel buffer for async processing
-func SetupRoutes(router *gin.Engine, linkService *services.LinkService, bufferSize int) {
+//   - clickService: business logic service for click analytics
+//   - apiKeyService: business logic service checking the API keys of /api/v1 requests
+//   - limiters: rate limiters for link creation and redirects; nil limiters disable their limit
+//   - cfg: application configuration (click buffer size, default redirect status, authentication...)
+func SetupRoutes(router *gin.Engine, linkService *services.LinkService, clickService *services.ClickService, apiKeyService *services.APIKeyService, limiters RateLimiters, cfg *config.Config) {
 	// Initialize the global click events channel if it hasn't been created yet
 	// This channel is used throughout the application for async click tracking
 	if ClickEventsChannel == nil {
-		ClickEventsChannel = make(chan models.ClickEvent, bufferSize)
+		ClickEventsChannel = make(chan models.ClickEvent, cfg.Analytics.BufferSize)
 	}
 
+	// Tag every request with an ID and log it once handled, before any route runs
+	router.Use(RequestIDMiddleware(), RequestLoggerMiddleware())
+
 	// Health Check Route - used for monitoring service availability
-	router.GET("/health", HealthCheckHandler)
+	router.GET("/health", HealthCheckHandler(linkService))
+
+	// Metrics Route - Prometheus scrape endpoint, registered before the catch-all redirect route
+	if cfg.Metrics.Enabled {
+		router.GET(cfg.Metrics.Path, gin.WrapH(metrics.Handler()))
+	}
 
 	// API Routes Group - all business logic endpoints under /api/v1 prefix
+	// Every request must carry an API key (unless auth.enabled is false); each route then checks its scope
 	api := router.Group("/api/v1")
+	if cfg.Auth.Enabled {
+		api.Use(APIKeyMiddleware(apiKeyService))
+	}
 	{
 		// POST endpoint for creating new shortened links (supports single and multiple URLs)
-		api.POST("/links", CreateShortLinkHandler(linkService))
+		// Rate limited per API key (per client IP when authentication is disabled), one token per URL
+		api.POST("/links", RequireScope(models.ScopeCreate), CreateShortLinkHandler(linkService, limiters.Create, cfg.Server.DeduplicateLinks))
+		// GET endpoint for listing links with cursor pagination, sorting and filters
+		api.GET("/links", RequireScope(models.ScopeReadStats), ListLinksHandler(linkService))
 		// GET endpoint for retrieving click statistics for a specific short code
-		api.GET("/links/:shortCode/stats", GetLinkStatsHandler(linkService))
+		api.GET("/links/:shortCode/stats", RequireScope(models.ScopeReadStats), GetLinkStatsHandler(linkService, clickService))
+		// GET endpoint for retrieving clicks bucketed by hour, day or week
+		api.GET("/links/:shortCode/stats/timeseries", RequireScope(models.ScopeReadStats), GetLinkTimeSeriesHandler(linkService, clickService))
+		// PATCH endpoint for changing the destination URL of an existing link
+		api.PATCH("/links/:shortCode", RequireScope(models.ScopeAdmin), UpdateLinkHandler(linkService))
+		// POST endpoints for temporarily turning a link off and back on
+		api.POST("/links/:shortCode/disable", RequireScope(models.ScopeAdmin), SetLinkDisabledHandler(linkService, true))
+		api.POST("/links/:shortCode/enable", RequireScope(models.ScopeAdmin), SetLinkDisabledHandler(linkService, false))
+		// DELETE endpoint for soft-deleting a link (click history is kept)
+		api.DELETE("/links/:shortCode", RequireScope(models.ScopeAdmin), DeleteLinkHandler(linkService))
 	}
 
-	// Redirection Route - handles the actual URL redirection at root level
+	// Redirection Routes - handle the actual URL redirection at root level
+	// Redirects stay public: no API key is ever required to follow a short link
 	// This is where users access their short URLs (e.g., localhost:8080/abc123)
-	router.GET("/:shortCode", RedirectHandler(linkService))
+	// Redirects are rate limited per client IP so a script cannot inflate click counts
+	redirectLimit := RateLimitMiddleware(limiters.Redirect, RateLimitRuleRedirect)
+	router.GET("/:shortCode", redirectLimit, RedirectHandler(linkService, cfg.Server.RedirectStatus))
+	// Links of namespaced workspaces live under the workspace slug (e.g., localhost:8080/acme/spring-sale)
+	// Gin requires the same wildcard name at the same position, so the first segment is still called shortCode here
+	router.GET("/:shortCode/:namespacedCode", redirectLimit, RedirectHandler(linkService, cfg.Server.RedirectStatus))
 }
 
 // HealthCheckHandler handles the /health route to verify service status
 // This endpoint is typically used by load balancers and monitoring systems
-func HealthCheckHandler(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+// When the short-code cache is enabled, its hit/miss counters are included for monitoring,
+// as well as the spooled/replayed/dropped counters of the click overflow spool
+func HealthCheckHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		response := gin.H{"status": "ok"}
+		if stats, ok := linkService.CacheStats(); ok {
+			response["link_cache"] = stats
+		}
+		if ClickSpool != nil {
+			response["click_spool"] = ClickSpool.Stats()
+		}
+		c.JSON(http.StatusOK, response)
+	}
 }
 
 // CreateLinkRequest represents the JSON request body for creating one or multiple links
 // This struct supports both backward compatibility (single URL) and new functionality (multiple URLs)
 // Supports both single URL and multiple URLs formats:
 // Single: {"long_url": "https://example.com"}
+// Single with custom alias: {"long_url": "https://example.com", "alias": "spring-sale"}
 // Multiple: {"long_urls": ["https://example.com", "https://google.com"]}
+// Expiring: {"long_url": "https://example.com", "expires_at": "2030-01-01T00:00:00Z", "max_clicks": 100}
+// Deduplicated: {"long_url": "https://example.com", "deduplicate": true}
 type CreateLinkRequest struct {
-	LongURL  string   `json:"long_url" binding:"omitempty,url"`       // Single URL (optional) - for backward compatibility
-	LongURLs []string `json:"long_urls" binding:"omitempty,dive,url"` // Multiple URLs (optional) - new feature
+	LongURL   string     `json:"long_url" binding:"omitempty,url"`       // Single URL (optional) - for backward compatibility
+	LongURLs  []string   `json:"long_urls" binding:"omitempty,dive,url"` // Multiple URLs (optional) - new feature
+	Alias     string     `json:"alias"`                                  // Custom short code (optional) - only valid for a single URL
+	ExpiresAt *time.Time `json:"expires_at"`                             // Expiration date in RFC 3339 format (optional)
+	MaxClicks int        `json:"max_clicks" binding:"omitempty,min=0"`   // Click budget (optional) - 0 means unlimited
+	// Redirect status (optional): 301/308 for permanent links, 302/307 for temporary ones; 0 uses the server default
+	RedirectStatus int `json:"redirect_status"`
+	// Return the existing link of the workspace for the same normalized URL instead of a new one (optional);
+	// omitted means the server default (server.deduplicate_links)
+	Deduplicate *bool `json:"deduplicate"`
 }
 
 // CreateLinkResponse represents the response for a single link creation
 // This struct is used both for single URL responses and as elements in the results array for multiple URLs
 type CreateLinkResponse struct {
-	ShortCode    string `json:"short_code"`      // The generated short code (e.g., "abc123")
-	LongURL      string `json:"long_url"`        // The original long URL that was shortened
-	FullShortURL string `json:"full_short_url"`  // Complete shortened URL ready to use
-	Success      bool   `json:"success"`         // Whether this particular URL was successfully shortened
-	Error        string `json:"error,omitempty"` // Error message if shortening failed (omitted if successful)
+	ShortCode    string `json:"short_code"`             // The generated short code (e.g., "abc123")
+	LongURL      string `json:"long_url"`               // The normalized destination, or the submitted URL if shortening failed
+	OriginalURL  string `json:"original_url,omitempty"` // The URL as submitted (omitted if shortening failed)
+	FullShortURL string `json:"full_short_url"`         // Complete shortened URL ready to use
+	Success      bool   `json:"success"`                // Whether this particular URL was successfully shortened
+	Deduplicated bool   `json:"deduplicated,omitempty"` // Whether an existing link was returned instead of a new one
+	Error        string `json:"error,omitempty"`        // Error message if shortening failed (omitted if successful)
 }
 
 // CreateLinksResponse represents the response for multiple link creation
@@ -83,17 +152,37 @@ type CreateLinksResponse struct {
 	} `json:"summary"` // Aggregate statistics for the batch operation
 }
 
+// maxBatchURLs is the maximum number of URLs accepted in one creation request,
+// so a single request cannot insert an unbounded number of links even when rate limiting is disabled.
+// The effective limit is lower when the create rate limit burst is smaller (see batchLimit).
+const maxBatchURLs = 100
+
+// batchLimit returns the number of URLs a creation request may contain: maxBatchURLs, or the burst
+// of the create limiter if smaller, since each URL costs one token and a bucket never holds more.
+func batchLimit(createLimiter ratelimit.Limiter) int {
+	if createLimiter == nil {
+		return maxBatchURLs
+	}
+	return max(1, min(maxBatchURLs, createLimiter.Burst()))
+}
+
 // CreateShortLinkHandler handles the creation of one or multiple shortened URLs
 // This handler supports both single URL (backward compatibility) and multiple URLs (new feature)
 // It automatically detects the request format and routes to appropriate processing logic
-func CreateShortLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
+// createLimiter charges one token per URL once the body is read (nil disables rate limiting);
+// deduplicateByDefault applies to requests that do not set "deduplicate" themselves
+func CreateShortLinkHandler(linkService *services.LinkService, createLimiter ratelimit.Limiter, deduplicateByDefault bool) gin.HandlerFunc {
+	maxURLs := batchLimit(createLimiter)
 	return func(c *gin.Context) {
 		var req CreateLinkRequest
 
 		// Attempt to bind the JSON request to the CreateLinkRequest struct
 		// Gin will validate URL formats based on the binding tags
+		// A malformed request still costs one token, so it cannot be retried in a tight loop
 		if err := c.ShouldBindJSON(&req); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			if allowRequest(c, createLimiter, RateLimitRuleCreate, 1) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			}
 			return
 		}
 
@@ -110,19 +199,51 @@ func CreateShortLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
 			urlsToProcess = append(urlsToProcess, req.LongURLs...)
 		}
 
+		// An oversized batch is rejected for one token, like a malformed body: it could never be
+		// allowed in full, and it must not be retried in a tight loop for free either
+		if len(urlsToProcess) > maxURLs {
+			if allowRequest(c, createLimiter, RateLimitRuleCreate, 1) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("At most %d URLs can be shortened per request", maxURLs)})
+			}
+			return
+		}
+
+		// Every URL creates a link, so a batch costs as many tokens as it has URLs
+		if !allowRequest(c, createLimiter, RateLimitRuleCreate, max(1, len(urlsToProcess))) {
+			return
+		}
+
 		// Validate that at least one URL was provided in the request
 		if len(urlsToProcess) == 0 {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Either 'long_url' or 'long_urls' must be provided"})
 			return
 		}
 
+		// A custom alias identifies exactly one link, so it cannot be shared by a batch
+		if req.Alias != "" && len(urlsToProcess) > 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "'alias' can only be used when shortening a single URL"})
+			return
+		}
+
+		opts := services.CreateLinkOptions{
+			Workspace:      requestWorkspace(c),
+			Alias:          req.Alias,
+			ExpiresAt:      req.ExpiresAt,
+			MaxClicks:      req.MaxClicks,
+			RedirectStatus: req.RedirectStatus,
+			Deduplicate:    deduplicateByDefault,
+		}
+		if req.Deduplicate != nil {
+			opts.Deduplicate = *req.Deduplicate
+		}
+
 		// Route to appropriate processing logic based on the number of URLs
 		if len(urlsToProcess) > 1 {
 			// Process multiple URLs with detailed result tracking
-			handleMultipleURLs(c, linkService, urlsToProcess)
+			handleMultipleURLs(c, linkService, urlsToProcess, opts)
 		} else {
 			// Process single URL with backward-compatible response format
-			handleSingleURL(c, linkService, urlsToProcess[0])
+			handleSingleURL(c, linkService, urlsToProcess[0], opts)
 		}
 	}
 }
@@ -130,10 +251,10 @@ func CreateShortLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
 // handleSingleURL processes a single URL request (maintains backward compatibility)
 // This function preserves the original API response format for single URL requests
 // ensuring existing clients continue to work without modification
-func handleSingleURL(c *gin.Context, linkService *services.LinkService, longURL string) {
+func handleSingleURL(c *gin.Context, linkService *services.LinkService, longURL string, opts services.CreateLinkOptions) {
 	// Call the LinkService to create the new shortened link
 	// The service handles short code generation, collision detection, and database storage
-	link, err := linkService.CreateLink(longURL)
+	link, created, err := linkService.CreateLink(c.Request.Context(), longURL, opts)
 	if err != nil {
 		// Handle the specific case where we can't generate a unique short code
 		// This can happen if the system is under heavy load or has many existing codes
@@ -141,28 +262,58 @@ func handleSingleURL(c *gin.Context, linkService *services.LinkService, longURL
 			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Unable to generate unique short code. Please try again later."})
 			return
 		}
+		// Handle a custom alias that is malformed or uses a reserved word, or invalid expiration settings
+		if errors.Is(err, customerrors.ErrInvalidShortCode) || errors.Is(err, customerrors.ErrShortCodeReserved) ||
+			errors.Is(err, customerrors.ErrInvalidExpiration) || errors.Is(err, customerrors.ErrInvalidRedirectStatus) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		// Handle a destination rejected by the destination policy
+		if isDestinationError(err) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		// Handle a custom alias that is already used by another link
+		if errors.Is(err, customerrors.ErrShortCodeTaken) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		// Handle an X-Workspace header naming a workspace that does not exist
+		if errors.Is(err, customerrors.ErrWorkspaceNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		// Handle any other unexpected errors during link creation
-		log.Printf("Error creating link: %v", err)
+		logging.FromContext(c.Request.Context()).Error("Error creating link", "long_url", longURL, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create short link"})
 		return
 	}
 
 	// Return the short code and long URL in the original JSON response format
 	// This maintains backward compatibility with existing API clients
-	c.JSON(http.StatusCreated, gin.H{
+	// An existing link returned by deduplication answers 200 instead of 201 since nothing was created
+	status := http.StatusCreated
+	if !created {
+		status = http.StatusOK
+	}
+	c.JSON(status, gin.H{
 		"short_code":     link.ShortCode,
 		"long_url":       link.LongURL,
-		"full_short_url": "http://localhost:8080/" + link.ShortCode, // TODO: Use cfg.Server.BaseURL for dynamic configuration
+		"original_url":   longURL,
+		"full_short_url": "http://localhost:8080/" + link.Path(), // TODO: Use cfg.Server.BaseURL for dynamic configuration
+		"deduplicated":   !created,
 	})
 }
 
 // handleMultipleURLs processes multiple URLs request with comprehensive error handling
 // This function provides detailed results for each URL and aggregate statistics
 // It ensures partial success scenarios are handled gracefully
-func handleMultipleURLs(c *gin.Context, linkService *services.LinkService, urls []string) {
+// With deduplication, URLs repeated within the batch share the result of their first occurrence
+func handleMultipleURLs(c *gin.Context, linkService *services.LinkService, urls []string, opts services.CreateLinkOptions) {
 	var results []CreateLinkResponse
 	successful := 0
 	failed := 0
+	firstResult := make(map[string]int) // Hash of the normalized destination -> index in results of its first occurrence
 
 	// Process each URL individually and track results
 	// This allows some URLs to succeed even if others fail
@@ -172,23 +323,53 @@ func handleMultipleURLs(c *gin.Context, linkService *services.LinkService, urls
 			LongURL: longURL, // Always include the original URL for traceability
 		}
 
+		// Reuse the outcome of an identical URL earlier in the batch, compared like stored links:
+		// after normalization, so URLs differing only by tracking parameters or dot segments match
+		if opts.Deduplicate {
+			destination, err := linkService.NormalizeDestination(longURL)
+			if err != nil {
+				destination = longURL // CreateLink reports the error; identical invalid URLs still share it
+			}
+			hash := urlnorm.Hash(destination)
+			if index, seen := firstResult[hash]; seen {
+				result = results[index]
+				if result.Success {
+					result.OriginalURL = longURL
+					result.Deduplicated = true
+					successful++
+				} else {
+					result.LongURL = longURL
+					failed++
+				}
+				results = append(results, result)
+				continue
+			}
+			firstResult[hash] = len(results)
+		}
+
 		// Attempt to create the short link for this URL
-		link, err := linkService.CreateLink(longURL)
+		link, created, err := linkService.CreateLink(c.Request.Context(), longURL, opts)
 		if err != nil {
 			// Handle error for this specific URL without affecting others
 			result.Success = false
 			if errors.Is(err, customerrors.ErrShortCodeGenerationFailed) {
 				result.Error = "Unable to generate unique short code"
+			} else if errors.Is(err, customerrors.ErrInvalidExpiration) || errors.Is(err, customerrors.ErrInvalidRedirectStatus) ||
+				errors.Is(err, customerrors.ErrWorkspaceNotFound) || isDestinationError(err) {
+				result.Error = err.Error()
 			} else {
 				result.Error = "Failed to create short link"
-				log.Printf("Error creating link for %s: %v", longURL, err)
+				logging.FromContext(c.Request.Context()).Error("Error creating link", "long_url", longURL, "error", err)
 			}
 			failed++
 		} else {
 			// Success case - populate all success fields
 			result.Success = true
+			result.Deduplicated = !created
 			result.ShortCode = link.ShortCode
-			result.FullShortURL = "http://localhost:8080/" + link.ShortCode // TODO: Use cfg.Server.BaseURL for dynamic configuration
+			result.LongURL = link.LongURL
+			result.OriginalURL = longURL
+			result.FullShortURL = "http://localhost:8080/" + link.Path() // TODO: Use cfg.Server.BaseURL for dynamic configuration
 			successful++
 		}
 
@@ -226,15 +407,26 @@ func handleMultipleURLs(c *gin.Context, linkService *services.LinkService, urls
 // RedirectHandler handles the redirection from a short URL to the original long URL
 // This is the core functionality that users experience when clicking short links
 // It also triggers asynchronous click tracking for analytics without blocking the redirect
-func RedirectHandler(linkService *services.LinkService) gin.HandlerFunc {
+// defaultRedirectStatus is used for links that do not define their own redirect status
+func RedirectHandler(linkService *services.LinkService, defaultRedirectStatus int) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		// Extract the short code from the URL path parameter
-		// This comes from routes like "/:shortCode" where shortCode is the generated identifier
-		shortCode := c.Param("shortCode")
+		// Record the outcome and latency of every resolution, whichever branch returns
+		start := time.Now()
+		defer func() {
+			metrics.ObserveRedirect(c.Writer.Status(), time.Since(start))
+		}()
+
+		// Extract the short code from the URL path parameters
+		// This comes from routes like "/:shortCode" where shortCode is the generated identifier,
+		// or "/:shortCode/:namespacedCode" where the first segment is the namespace of a workspace
+		namespace, shortCode := "", c.Param("shortCode")
+		if namespacedCode := c.Param("namespacedCode"); namespacedCode != "" {
+			namespace, shortCode = shortCode, namespacedCode
+		}
 
 		// Retrieve the original long URL associated with this short code
 		// This is the database lookup that resolves the short code to its target
-		link, err := linkService.GetLinkByShortCode(shortCode)
+		link, err := linkService.GetLinkByShortCode(namespace, shortCode)
 		if err != nil {
 			// Handle the case where the short code doesn't exist in our database
 			if errors.Is(err, customerrors.ErrShortCodeNotFound) {
@@ -242,7 +434,24 @@ func RedirectHandler(linkService *services.LinkService) gin.HandlerFunc {
 				return
 			}
 			// Handle any other unexpected database or service errors
-			log.Printf("Error retrieving link for %s: %v", shortCode, err)
+			logging.FromContext(c.Request.Context()).Error("Error retrieving link", "short_code", shortCode, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		// Refuse to redirect links that are disabled, past their date or out of clicks
+		// 403 Forbidden marks a link its owner turned off (it may come back),
+		// 410 Gone tells clients the link existed but will not come back
+		if err := linkService.CheckLinkAvailable(link); err != nil {
+			if errors.Is(err, customerrors.ErrLinkDisabled) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Short URL has been disabled"})
+				return
+			}
+			if errors.Is(err, customerrors.ErrLinkExpired) {
+				c.JSON(http.StatusGone, gin.H{"error": "Short URL has expired"})
+				return
+			}
+			logging.FromContext(c.Request.Context()).Error("Error checking availability of link", "short_code", shortCode, "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 			return
 		}
@@ -250,10 +459,12 @@ func RedirectHandler(linkService *services.LinkService) gin.HandlerFunc {
 		// Create a ClickEvent with all relevant information for analytics
 		// This captures the context of the click for later analysis
 		clickEvent := models.ClickEvent{
-			LinkID:    link.ID,                   // Database ID of the link that was clicked
-			Timestamp: time.Now(),                // Exact time when the click occurred
-			UserAgent: c.GetHeader("User-Agent"), // Browser/client information for device analytics
-			IPAddress: c.ClientIP(),              // Client IP address for geographic analytics
+			LinkID:    link.ID,                                            // Database ID of the link that was clicked
+			Timestamp: time.Now().UTC(),                                   // Exact time when the click occurred (UTC keeps stored values comparable)
+			UserAgent: c.GetHeader("User-Agent"),                          // Browser/client information for device analytics
+			IPAddress: c.ClientIP(),                                       // Client IP address for geographic analytics
+			Referrer:  services.NormalizeReferrer(c.GetHeader("Referer")), // Source host for channel analytics
+			RequestID: logging.RequestID(c.Request.Context()),             // Lets worker logs be traced back to this request
 		}
 
 		// Send the ClickEvent to the processing channel using non-blocking select
@@ -261,49 +472,379 @@ func RedirectHandler(linkService *services.LinkService) gin.HandlerFunc {
 		select {
 		case ClickEventsChannel <- clickEvent:
 			// Event successfully queued for asynchronous processing
-			log.Printf("Click event queued for link %s (ID: %d)", shortCode, link.ID)
+			logging.FromContext(c.Request.Context()).Debug("Click event queued", "short_code", shortCode, "link_id", link.ID)
 		default:
-			// Channel buffer is full - we drop the event rather than blocking the user
-			// This prioritizes user experience over perfect analytics in high-load scenarios
-			log.Printf("WARNING: ClickEventsChannel is full, dropping click event for %s (ID: %d)", shortCode, link.ID)
+			// Channel buffer is full - we never block the user, the event goes to the on-disk spool
+			// and is replayed into the workers once the pressure drops
+			if ClickSpool != nil {
+				ClickSpool.Append(clickEvent)
+			} else {
+				logging.FromContext(c.Request.Context()).Warn("ClickEventsChannel is full, dropping click event", "short_code", shortCode, "link_id", link.ID)
+			}
 		}
 
-		// Perform the HTTP 302 redirect to the original long URL
+		// Perform the redirect to the original long URL with the link's status (302 by default)
 		// This is the primary function - getting the user to their intended destination
-		c.Redirect(http.StatusFound, link.LongURL)
+		status := link.EffectiveRedirectStatus(defaultRedirectStatus)
+		if services.IsPermanentRedirect(status) && link.CanExpire() {
+			// A cached permanent redirect would keep working after the link expires (server default of 301/308)
+			c.Header("Cache-Control", "private, max-age=0")
+		}
+		c.Redirect(status, link.LongURL)
 	}
 }
 
 // GetLinkStatsHandler handles the retrieval of statistics for a specific link
 // This endpoint provides analytics data including click counts and link metadata
 // Used by both the API and CLI to display usage statistics
-func GetLinkStatsHandler(linkService *services.LinkService) gin.HandlerFunc {
+func GetLinkStatsHandler(linkService *services.LinkService, clickService *services.ClickService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Extract the short code from the URL path parameter
 		shortCode := c.Param("shortCode")
 
 		// Call the LinkService to get both link information and aggregated click statistics
 		// This single call provides all the data needed for a comprehensive stats response
-		link, totalClicks, err := linkService.GetLinkStats(shortCode)
+		// Only links of the caller's workspace are visible
+		link, totalClicks, err := linkService.GetLinkStats(requestWorkspace(c), shortCode)
 		if err != nil {
-			// Handle the case where the requested short code doesn't exist
+			// Handle the case where the requested short code doesn't exist in this workspace
 			if errors.Is(err, customerrors.ErrShortCodeNotFound) {
 				c.JSON(http.StatusNotFound, gin.H{"error": "Short URL not found"})
 				return
 			}
+			if errors.Is(err, customerrors.ErrWorkspaceNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
 			// Handle any other database or service errors during stats retrieval
-			log.Printf("Error retrieving stats for %s: %v", shortCode, err)
+			logging.FromContext(c.Request.Context()).Error("Error retrieving stats", "short_code", shortCode, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		// Classify the clicks by client so bots can be told apart from humans
+		userAgents, err := clickService.GetUserAgentBreakdown(link.ID)
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Error("Error retrieving user agent breakdown", "short_code", shortCode, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		// Group the clicks by country (filled by the workers when geolocation is enabled)
+		countries, err := clickService.GetCountryBreakdown(link.ID)
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Error("Error retrieving country breakdown", "short_code", shortCode, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		// Count distinct visitors so repeated refreshes by one person count once per day
+		uniqueVisitors, err := clickService.GetUniqueVisitors(link.ID)
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Error("Error counting unique visitors", "short_code", shortCode, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		// Find which sites send the most traffic to this link
+		referrers, err := clickService.GetTopReferrers(link.ID)
+		if err != nil {
+			logging.FromContext(c.Request.Context()).Error("Error retrieving referrers", "short_code", shortCode, "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 			return
 		}
 
 		// Return comprehensive statistics in JSON format
 		// Includes link metadata and usage analytics
+		now := time.Now()
+		response := gin.H{
+			"short_code":      link.ShortCode,                               // The short code identifier
+			"workspace":       link.Workspace,                               // The workspace owning the link
+			"short_path":      link.Path(),                                  // Path of the short URL, namespace included
+			"long_url":        link.LongURL,                                 // The (normalized) destination URL
+			"original_url":    link.DisplayURL(),                            // The destination as it was submitted
+			"total_clicks":    totalClicks,                                  // Aggregate count of all clicks
+			"unique_visitors": uniqueVisitors,                               // Distinct IP + user agent pairs per day
+			"human_clicks":    userAgents.HumanClicks,                       // Clicks from real browsers
+			"bot_clicks":      userAgents.BotClicks,                         // Clicks from crawlers and scripts
+			"user_agents":     userAgents,                                   // Browser, OS and device breakdown
+			"countries":       countries,                                    // Clicks per country code
+			"top_referrers":   referrers,                                    // Clicks per referring host
+			"created_at":      link.CreatedAt.Format("2006-01-02 15:04:05"), // Human-readable creation timestamp
+			"expired":         link.IsExpired(now, totalClicks),             // Whether the link ran out of time or clicks
+			"disabled":        link.Disabled,                                // Whether the link was turned off by its owner
+		}
+
+		// Expiration details are only reported for links that have them
+		if remaining := link.RemainingLifetime(now); remaining != nil {
+			response["expires_at"] = link.ExpiresAt.Format(time.RFC3339)
+			response["remaining_lifetime_seconds"] = int64(remaining.Seconds())
+		}
+		if remaining := link.RemainingClicks(totalClicks); remaining != nil {
+			response["max_clicks"] = link.MaxClicks
+			response["remaining_clicks"] = *remaining
+		}
+
+		// The redirect status is only reported when the link overrides the server default
+		if link.RedirectStatus != 0 {
+			response["redirect_status"] = link.RedirectStatus
+		}
+
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// UpdateLinkRequest represents the JSON request body for changing the destination of a link
+type UpdateLinkRequest struct {
+	LongURL string `json:"long_url" binding:"required,url"` // The new destination URL
+}
+
+// UpdateLinkHandler handles changing the long URL of an existing short code
+// The short code itself never changes, so links already shared keep working
+func UpdateLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		var req UpdateLinkRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+			return
+		}
+
+		link, err := linkService.UpdateLongURL(c.Request.Context(), requestWorkspace(c), shortCode, req.LongURL)
+		if err != nil {
+			if isDestinationError(err) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			respondLinkLookupError(c, shortCode, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"short_code":   link.ShortCode,
+			"long_url":     link.LongURL,
+			"original_url": link.OriginalURL,
+			"disabled":     link.Disabled,
+			"updated_at":   link.UpdatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+}
+
+// SetLinkDisabledHandler handles turning a link off (disabled=true) or back on (disabled=false)
+// The same handler backs both the /disable and /enable routes
+func SetLinkDisabledHandler(linkService *services.LinkService, disabled bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		link, err := linkService.SetLinkDisabled(requestWorkspace(c), shortCode, disabled)
+		if err != nil {
+			respondLinkLookupError(c, shortCode, err)
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"short_code": link.ShortCode,
+			"long_url":   link.LongURL,
+			"disabled":   link.Disabled,
+		})
+	}
+}
+
+// DeleteLinkHandler handles soft-deleting a link
+// The link stops resolving immediately but its click history stays in the database
+func DeleteLinkHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+
+		if err := linkService.DeleteLink(requestWorkspace(c), shortCode); err != nil {
+			respondLinkLookupError(c, shortCode, err)
+			return
+		}
+
+		c.Status(http.StatusNoContent)
+	}
+}
+
+// respondLinkLookupError writes the error response shared by the link management handlers
+// Unknown short codes and workspaces map to 404, anything else is logged and reported as a 500
+func respondLinkLookupError(c *gin.Context, shortCode string, err error) {
+	if errors.Is(err, customerrors.ErrShortCodeNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Short URL not found"})
+		return
+	}
+	if errors.Is(err, customerrors.ErrWorkspaceNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	logging.FromContext(c.Request.Context()).Error("Error managing link", "short_code", shortCode, "error", err)
+	c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+}
+
+// isDestinationError reports whether a link destination was refused by the destination policy
+// Both cases are client errors: the caller has to pick another URL
+func isDestinationError(err error) bool {
+	var blocked customerrors.ErrDestinationBlocked
+	return errors.As(err, &blocked) || errors.Is(err, customerrors.ErrInvalidURL)
+}
+
+// LinkSummary represents one link in the response of the list endpoint
+type LinkSummary struct {
+	ShortCode    string     `json:"short_code"`             // The short code identifier
+	LongURL      string     `json:"long_url"`               // The destination URL
+	OriginalURL  string     `json:"original_url"`           // The destination as it was submitted
+	Domain       string     `json:"domain"`                 // Host of the destination URL
+	TotalClicks  int        `json:"total_clicks"`           // Number of recorded clicks
+	HealthStatus string     `json:"health_status"`          // Last monitor result: unknown, up or down
+	Disabled     bool       `json:"disabled"`               // Whether the link was turned off by its owner
+	CreatedAt    time.Time  `json:"created_at"`             // Creation timestamp
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`   // Expiration date, if any
+	LastChecked  *time.Time `json:"last_checked,omitempty"` // Time of the last monitor check, if any
+}
+
+// ListLinksResponse represents the response of the list endpoint
+type ListLinksResponse struct {
+	Links      []LinkSummary `json:"links"`                 // Links of the current page
+	NextCursor string        `json:"next_cursor,omitempty"` // Pass as ?cursor= to get the next page; omitted on the last page
+}
+
+// ListLinksHandler handles paginated link listings
+// Supported query parameters:
+//   - limit: page size (1-100, default 20)
+//   - cursor: next_cursor value from the previous page
+//   - sort: created_at (default) or clicks
+//   - order: desc (default) or asc
+//   - domain: destination domain, subdomains included
+//   - created_after / created_before: RFC 3339 timestamps or YYYY-MM-DD dates
+//   - health: unknown, up or down
+func ListLinksHandler(linkService *services.LinkService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		opts := services.ListLinksOptions{
+			SortBy: c.Query("sort"),
+			Cursor: c.Query("cursor"),
+			Filter: repository.LinkListFilter{
+				Workspace:    requestWorkspace(c), // Never list the links of another workspace
+				Domain:       c.Query("domain"),
+				HealthStatus: c.Query("health"),
+			},
+		}
+
+		// Parse the query parameters that are not plain strings
+		if limit := c.Query("limit"); limit != "" {
+			value, err := strconv.Atoi(limit)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'limit' parameter: must be an integer"})
+				return
+			}
+			opts.Limit = value
+		}
+		switch c.DefaultQuery("order", "desc") {
+		case "asc":
+			opts.Ascending = true
+		case "desc":
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'order' parameter: must be 'asc' or 'desc'"})
+			return
+		}
+		var err error
+		if opts.Filter.CreatedAfter, err = services.ParseOptionalDate(c.Query("created_after")); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'created_after' parameter: " + err.Error()})
+			return
+		}
+		if opts.Filter.CreatedBefore, err = services.ParseOptionalDate(c.Query("created_before")); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'created_before' parameter: " + err.Error()})
+			return
+		}
+
+		page, err := linkService.ListLinks(opts)
+		if err != nil {
+			if errors.Is(err, customerrors.ErrInvalidListQuery) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			logging.FromContext(c.Request.Context()).Error("Error listing links", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		response := ListLinksResponse{
+			Links:      make([]LinkSummary, 0, len(page.Links)),
+			NextCursor: page.NextCursor,
+		}
+		for _, link := range page.Links {
+			response.Links = append(response.Links, LinkSummary{
+				ShortCode:    link.ShortCode,
+				LongURL:      link.LongURL,
+				OriginalURL:  link.DisplayURL(),
+				Domain:       link.Domain,
+				TotalClicks:  link.ClickCount,
+				HealthStatus: link.HealthStatus,
+				Disabled:     link.Disabled,
+				CreatedAt:    link.CreatedAt,
+				ExpiresAt:    link.ExpiresAt,
+				LastChecked:  link.LastCheckedAt,
+			})
+		}
+		c.JSON(http.StatusOK, response)
+	}
+}
+
+// GetLinkTimeSeriesHandler handles the retrieval of a link's clicks bucketed over time
+// Supported query parameters:
+//   - granularity: hour, day (default) or week
+//   - from / to: RFC 3339 timestamps or YYYY-MM-DD dates; default to a window ending now
+func GetLinkTimeSeriesHandler(linkService *services.LinkService, clickService *services.ClickService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		shortCode := c.Param("shortCode")
+		granularity := c.DefaultQuery("granularity", repository.GranularityDay)
+
+		from, err := services.ParseOptionalDate(c.Query("from"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'from' parameter: " + err.Error()})
+			return
+		}
+		to, err := services.ParseOptionalDate(c.Query("to"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid 'to' parameter: " + err.Error()})
+			return
+		}
+
+		link, err := linkService.GetWorkspaceLink(requestWorkspace(c), shortCode)
+		if err != nil {
+			respondLinkLookupError(c, shortCode, err)
+			return
+		}
+
+		var fromTime, toTime time.Time
+		if from != nil {
+			fromTime = *from
+		}
+		if to != nil {
+			toTime = *to
+		}
+		series, err := clickService.GetClickTimeSeries(link.ID, granularity, fromTime, toTime)
+		if err != nil {
+			if errors.Is(err, customerrors.ErrInvalidTimeRange) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			logging.FromContext(c.Request.Context()).Error("Error retrieving time series", "short_code", shortCode, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		points := make([]gin.H, 0, len(series))
+		total := 0
+		for _, point := range series {
+			points = append(points, gin.H{"start": point.Start.Format(time.RFC3339), "count": point.Count})
+			total += point.Count
+		}
+
 		c.JSON(http.StatusOK, gin.H{
-			"short_code":   link.ShortCode,                               // The short code identifier
-			"long_url":     link.LongURL,                                 // The original long URL
-			"total_clicks": totalClicks,                                  // Aggregate count of all clicks
-			"created_at":   link.CreatedAt.Format("2006-01-02 15:04:05"), // Human-readable creation timestamp
+			"short_code":  link.ShortCode,
+			"granularity": granularity,
+			"total":       total, // Clicks within the returned range only
+			"points":      points,
 		})
 	}
 }
@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/ratelimit"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/axellelanca/urlshortener/internal/urlnorm"
+	"github.com/gin-gonic/gin"
+)
+
+// newTestCreateRouter serves CreateShortLinkHandler on POST /links over in-memory repositories.
+func newTestCreateRouter(limiter ratelimit.Limiter) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	store := repository.NewMemoryStore()
+	linkService := services.NewLinkService(repository.NewMemoryLinkRepository(store), repository.NewMemoryWorkspaceRepository(store), nil, nil)
+	router := gin.New()
+	router.POST("/links", CreateShortLinkHandler(linkService, limiter, false))
+	return router
+}
+
+// postLinks sends a creation request for the given number of distinct URLs.
+func postLinks(router *gin.Engine, count int) *httptest.ResponseRecorder {
+	urls := make([]string, count)
+	for i := range urls {
+		urls[i] = fmt.Sprintf(`"https://example.com/%d"`, i)
+	}
+	body := `{"long_urls": [` + strings.Join(urls, ",") + `]}`
+	if count == 1 {
+		body = `{"long_url": ` + urls[0] + `}`
+	}
+	request := httptest.NewRequest(http.MethodPost, "/links", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+	return recorder
+}
+
+func TestCreateLinksChargesOneTokenPerURL(t *testing.T) {
+	router := newTestCreateRouter(ratelimit.NewMemoryLimiter(ratelimit.Rule{RequestsPerMinute: 1, Burst: 5}))
+
+	if response := postLinks(router, 4); response.Code != http.StatusCreated || response.Header().Get("X-RateLimit-Remaining") != "1" {
+		t.Fatalf("batch of 4: status %d, remaining %s", response.Code, response.Header().Get("X-RateLimit-Remaining"))
+	}
+	if response := postLinks(router, 2); response.Code != http.StatusTooManyRequests {
+		t.Fatalf("batch of 2 with 1 token left: expected 429, got %d", response.Code)
+	}
+	if response := postLinks(router, 1); response.Code != http.StatusCreated {
+		t.Fatalf("single URL with 1 token left: expected 201, got %d", response.Code)
+	}
+}
+
+func TestCreateLinksRejectsBatchesBeyondTheLimits(t *testing.T) {
+	router := newTestCreateRouter(ratelimit.NewMemoryLimiter(ratelimit.Rule{RequestsPerMinute: 1, Burst: 5}))
+	response := postLinks(router, 6)
+	if response.Code != http.StatusBadRequest || !strings.Contains(response.Body.String(), "At most 5 URLs") {
+		t.Fatalf("batch larger than the burst: status %d, body %s", response.Code, response.Body.String())
+	}
+	// The rejection costs one token, like a malformed body
+	if remaining := response.Header().Get("X-RateLimit-Remaining"); remaining != "4" {
+		t.Fatalf("rejected batch: remaining %s, want 4", remaining)
+	}
+	if response := postLinks(router, 4); response.Code != http.StatusCreated {
+		t.Fatalf("batch within the remaining tokens: expected 201, got %d", response.Code)
+	}
+	if response := postLinks(router, 6); response.Code != http.StatusTooManyRequests {
+		t.Fatalf("oversized batch with an empty bucket: expected 429, got %d", response.Code)
+	}
+
+	// A burst above maxBatchURLs leaves the batch size capped
+	generous := newTestCreateRouter(ratelimit.NewMemoryLimiter(ratelimit.Rule{RequestsPerMinute: 1000, Burst: 1000}))
+	if response := postLinks(generous, maxBatchURLs+1); response.Code != http.StatusBadRequest || response.Header().Get("X-RateLimit-Remaining") != "999" {
+		t.Fatalf("batch larger than %d: status %d, remaining %s", maxBatchURLs, response.Code, response.Header().Get("X-RateLimit-Remaining"))
+	}
+
+	unlimited := newTestCreateRouter(nil)
+	if response := postLinks(unlimited, maxBatchURLs+1); response.Code != http.StatusBadRequest {
+		t.Fatalf("batch larger than %d: expected 400, got %d", maxBatchURLs, response.Code)
+	}
+	if response := postLinks(unlimited, maxBatchURLs); response.Code != http.StatusCreated {
+		t.Fatalf("batch of %d: expected 201, got %d", maxBatchURLs, response.Code)
+	}
+}
+
+// countingLinkRepository counts the duplicate lookups made by LinkService.CreateLink.
+type countingLinkRepository struct {
+	repository.LinkRepository
+	duplicateLookups int
+}
+
+func (r *countingLinkRepository) FindLinksByLongURLHash(workspace, longURLHash string) ([]models.Link, error) {
+	r.duplicateLookups++
+	return r.LinkRepository.FindLinksByLongURLHash(workspace, longURLHash)
+}
+
+func TestCreateLinksDeduplicatesNormalizedURLsWithinBatch(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := repository.NewMemoryStore()
+	linkRepo := &countingLinkRepository{LinkRepository: repository.NewMemoryLinkRepository(store)}
+	normalizer := urlnorm.New(urlnorm.Options{LowercaseHost: true, CleanPath: true, StripTrackingParams: true, TrackingParams: []string{"utm_*"}})
+	linkService := services.NewLinkService(linkRepo, repository.NewMemoryWorkspaceRepository(store), nil, normalizer)
+	router := gin.New()
+	router.POST("/links", CreateShortLinkHandler(linkService, nil, true))
+
+	body := `{"long_urls": ["https://example.com/a?utm_source=x", "https://EXAMPLE.com/b/../a", "https://example.com/other"]}`
+	request := httptest.NewRequest(http.MethodPost, "/links", strings.NewReader(body))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	var response CreateLinksResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("status %d, invalid body: %v", recorder.Code, err)
+	}
+	results := response.Results
+	if len(results) != 3 || !results[0].Success || !results[1].Success || !results[2].Success {
+		t.Fatalf("unexpected results %+v", results)
+	}
+	if results[1].ShortCode != results[0].ShortCode || !results[1].Deduplicated {
+		t.Errorf("equivalent URLs got different links: %+v", results[:2])
+	}
+	if results[1].OriginalURL != "https://EXAMPLE.com/b/../a" {
+		t.Errorf("original URL of the duplicate not kept: %s", results[1].OriginalURL)
+	}
+	if results[2].ShortCode == results[0].ShortCode {
+		t.Error("distinct URLs share a link")
+	}
+	// The equivalent URL is answered from the batch, without going through CreateLink again
+	if linkRepo.duplicateLookups != 2 {
+		t.Errorf("expected 2 duplicate lookups for 2 distinct destinations, got %d", linkRepo.duplicateLookups)
+	}
+}
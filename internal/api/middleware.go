@@ -0,0 +1,252 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	customerrors "github.com/axellelanca/urlshortener/internal/errors"
+	"github.com/axellelanca/urlshortener/internal/logging"
+	"github.com/axellelanca/urlshortener/internal/metrics"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/ratelimit"
+	"github.com/axellelanca/urlshortener/internal/services"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader is the HTTP header carrying the request ID, read from clients and proxies
+// and echoed in every response so a user report can be matched with the server logs.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the size of a request ID accepted from a client.
+const maxRequestIDLength = 128
+
+// APIKeyHeader is the HTTP header carrying the API key, as an alternative to "Authorization: Bearer <key>".
+const APIKeyHeader = "X-API-Key"
+
+// WorkspaceHeader is the HTTP header selecting the workspace when API key authentication is disabled.
+// With authentication enabled it is ignored: the workspace is always the one the key is bound to.
+const WorkspaceHeader = "X-Workspace"
+
+// Names of the rate limit rules, used in metrics and logs.
+const (
+	RateLimitRuleCreate   = "create"   // Link creation (POST /api/v1/links)
+	RateLimitRuleRedirect = "redirect" // Short URL redirects
+)
+
+// RateLimiters groups the limiters applied by SetupRoutes.
+// A nil limiter disables its rule; a limiter backed by a shared store can be set instead of the in-process one.
+type RateLimiters struct {
+	Create   ratelimit.Limiter // Applied to POST /api/v1/links
+	Redirect ratelimit.Limiter // Applied to short URL redirects
+}
+
+// apiKeyContextKey is the Gin context key under which APIKeyMiddleware stores the authenticated key.
+const apiKeyContextKey = "api_key"
+
+// RequestIDMiddleware assigns a request ID to every request.
+// An ID provided by the client (or a proxy in front of the server) in X-Request-ID is kept
+// when it is reasonable; otherwise a random one is generated. The ID is stored in the request
+// context, where logging.FromContext picks it up in handlers, services and click workers.
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = newRequestID()
+		}
+
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), requestID))
+		c.Header(RequestIDHeader, requestID)
+		c.Next()
+	}
+}
+
+// RequestLoggerMiddleware writes one structured log line per request once it has been handled.
+// It replaces Gin's default text logger so access logs follow logging.level and logging.format.
+// Server errors are logged at error level, client errors at warn level and the rest at info level.
+func RequestLoggerMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+			slog.Int("size", c.Writer.Size()),
+		}
+		if key := authenticatedKey(c); key != nil {
+			attrs = append(attrs, slog.Uint64("api_key_id", uint64(key.ID)), slog.String("workspace", key.Workspace))
+		}
+		logging.FromContext(c.Request.Context()).LogAttrs(c.Request.Context(), level, "HTTP request", attrs...)
+	}
+}
+
+// APIKeyMiddleware rejects requests that do not carry a valid API key with 401 Unauthorized.
+// The key is read from "Authorization: Bearer <key>" or from the X-API-Key header; the matching
+// record is stored in the Gin context for RequireScope. Unknown and revoked keys get the same answer.
+func APIKeyMiddleware(apiKeyService *services.APIKeyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, err := apiKeyService.Authenticate(apiKeyFromRequest(c))
+		if err != nil {
+			if errors.Is(err, customerrors.ErrInvalidAPIKey) {
+				c.Header("WWW-Authenticate", `Bearer realm="api"`)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Missing or invalid API key"})
+				return
+			}
+			logging.FromContext(c.Request.Context()).Error("Error authenticating API key", "error", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
+			return
+		}
+
+		c.Set(apiKeyContextKey, key)
+		c.Next()
+	}
+}
+
+// RequireScope rejects requests whose API key does not grant the given scope with 403 Forbidden.
+// It must run after APIKeyMiddleware; when authentication is disabled (no key in the context) it lets everything through.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, exists := c.Get(apiKeyContextKey); !exists {
+			c.Next()
+			return
+		}
+		if key := authenticatedKey(c); key == nil || !key.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "API key lacks the '" + scope + "' scope"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// requestWorkspace returns the slug of the workspace a management request acts on:
+// the workspace of the authenticated API key, or, when authentication is disabled,
+// the X-Workspace header falling back to the default workspace.
+func requestWorkspace(c *gin.Context) string {
+	if key := authenticatedKey(c); key != nil {
+		return key.Workspace
+	}
+	if workspace := strings.TrimSpace(c.GetHeader(WorkspaceHeader)); workspace != "" {
+		return workspace
+	}
+	return models.DefaultWorkspace
+}
+
+// RateLimitMiddleware rejects requests of clients that exceeded their limit with 429 Too Many Requests
+// and a Retry-After header. Clients are identified by their API key when the request is authenticated
+// (it must then run after APIKeyMiddleware), by their IP address otherwise.
+// Every response carries X-RateLimit-Limit and X-RateLimit-Remaining so well-behaved clients can pace themselves.
+// If the limiter fails (e.g. a shared store is unreachable), the request is let through rather than rejected.
+func RateLimitMiddleware(limiter ratelimit.Limiter, rule string) gin.HandlerFunc {
+	if limiter == nil {
+		return func(c *gin.Context) { c.Next() }
+	}
+	return func(c *gin.Context) {
+		if allowRequest(c, limiter, rule, 1) {
+			c.Next()
+		}
+	}
+}
+
+// allowRequest charges a request costing the given number of tokens to its client, like RateLimitMiddleware.
+// Callers must not charge more than limiter.Burst() tokens, or the request could never be allowed
+// (CreateShortLinkHandler keeps batches within it, see batchLimit).
+// Returns false if the request was rejected; the response has then been written.
+func allowRequest(c *gin.Context, limiter ratelimit.Limiter, rule string, tokens int) bool {
+	if limiter == nil {
+		return true
+	}
+	decision, err := limiter.AllowN(c.Request.Context(), rateLimitKey(c), tokens)
+	if err != nil {
+		logging.FromContext(c.Request.Context()).Warn("Rate limiter unavailable, request allowed", "rule", rule, "error", err)
+		return true
+	}
+
+	c.Header("X-RateLimit-Limit", strconv.Itoa(decision.Limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+	if decision.Allowed {
+		return true
+	}
+	// Retry-After is expressed in whole seconds, rounded up so the client never retries too early
+	retryAfter := int(math.Ceil(decision.RetryAfter.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	metrics.RateLimited(rule)
+	c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded, retry later"})
+	return false
+}
+
+// rateLimitKey identifies the client of a request for rate limiting: its API key, or its IP address.
+func rateLimitKey(c *gin.Context) string {
+	if key := authenticatedKey(c); key != nil {
+		return "key:" + strconv.FormatUint(uint64(key.ID), 10)
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// apiKeyFromRequest extracts the plain API key from the Authorization or X-API-Key header.
+func apiKeyFromRequest(c *gin.Context) string {
+	if authorization := c.GetHeader("Authorization"); authorization != "" {
+		scheme, token, found := strings.Cut(authorization, " ")
+		if found && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+	}
+	return strings.TrimSpace(c.GetHeader(APIKeyHeader))
+}
+
+// authenticatedKey returns the API key stored by APIKeyMiddleware, or nil.
+func authenticatedKey(c *gin.Context) *models.APIKey {
+	value, exists := c.Get(apiKeyContextKey)
+	if !exists {
+		return nil
+	}
+	key, _ := value.(*models.APIKey)
+	return key
+}
+
+// validRequestID reports whether a client-provided request ID can be reused as-is:
+// non-empty, bounded in length and limited to printable ASCII so it cannot forge log lines.
+func validRequestID(requestID string) bool {
+	if requestID == "" || len(requestID) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(requestID); i++ {
+		if requestID[i] < 0x21 || requestID[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID generates a random 128-bit request ID encoded in hexadecimal.
+func newRequestID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		// crypto/rand does not fail on supported platforms; fall back to a time-based ID just in case
+		return hex.EncodeToString([]byte(time.Now().UTC().Format(time.RFC3339Nano)))
+	}
+	return hex.EncodeToString(id)
+}
@@ -2,7 +2,7 @@ package config
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"strings"
 
 	"github.com/spf13/viper"
@@ -13,25 +13,108 @@ import (
 type Config struct {
 	// Server configuration section containing HTTP server settings
 	Server struct {
-		Port    int    `mapstructure:"port"`     // HTTP server port (default: 8080)
-		BaseURL string `mapstructure:"base_url"` // Base URL for generating short links
+		Port                   int    `mapstructure:"port"`                     // HTTP server port (default: 8080)
+		BaseURL                string `mapstructure:"base_url"`                 // Base URL for generating short links
+		RedirectStatus         int    `mapstructure:"redirect_status"`          // Default redirect status for links without their own (301, 302, 307 or 308)
+		DeduplicateLinks       bool   `mapstructure:"deduplicate_links"`        // Return the existing link for an already shortened URL unless the request says otherwise
+		ShutdownTimeoutSeconds int    `mapstructure:"shutdown_timeout_seconds"` // Maximum time to drain requests on shutdown, then again to flush clicks
+		// Addresses or CIDRs of the reverse proxies allowed to set X-Forwarded-For / X-Real-IP;
+		// the client IP (rate limiting, click analytics) is read from those headers only when they come from these proxies
+		TrustedProxies []string `mapstructure:"trusted_proxies"`
 	} `mapstructure:"server"`
 
-	// Database configuration section for SQLite settings
+	// Database configuration section: backend, connection string and pool settings
 	Database struct {
-		Name string `mapstructure:"name"` // SQLite database file name
+		Driver                 string `mapstructure:"driver"`                    // Database backend: "sqlite" or "postgres"
+		DSN                    string `mapstructure:"dsn"`                       // Connection string; for SQLite defaults to Name
+		Name                   string `mapstructure:"name"`                      // SQLite database file name (used when DSN is empty)
+		MaxOpenConns           int    `mapstructure:"max_open_conns"`            // Maximum number of open connections (0 = unlimited)
+		MaxIdleConns           int    `mapstructure:"max_idle_conns"`            // Maximum number of idle connections kept in the pool
+		ConnMaxLifetimeMinutes int    `mapstructure:"conn_max_lifetime_minutes"` // Maximum lifetime of a connection (0 = forever)
 	} `mapstructure:"database"`
 
+	// Cache configuration for short-code resolution during redirects
+	Cache struct {
+		Enabled            bool `mapstructure:"enabled"`              // Whether redirects resolve short codes through the in-process cache
+		Size               int  `mapstructure:"size"`                 // Maximum number of cached short codes (LRU eviction beyond it)
+		TTLSeconds         int  `mapstructure:"ttl_seconds"`          // How long a found link stays cached
+		NegativeTTLSeconds int  `mapstructure:"negative_ttl_seconds"` // How long an unknown short code stays cached as missing (0 = disabled)
+	} `mapstructure:"cache"`
+
 	// Analytics configuration for asynchronous click tracking
 	Analytics struct {
-		BufferSize  int `mapstructure:"buffer_size"`  // Size of the click event channel buffer
-		WorkerCount int `mapstructure:"worker_count"` // Number of worker goroutines for processing clicks
+		BufferSize         int    `mapstructure:"buffer_size"`                   // Size of the click event channel buffer
+		WorkerCount        int    `mapstructure:"worker_count"`                  // Number of worker goroutines for processing clicks
+		VisitorSalt        string `mapstructure:"visitor_salt"`                  // Secret mixed into unique-visitor hashes so IPs cannot be recovered
+		BatchSize          int    `mapstructure:"batch_size"`                    // Number of clicks a worker accumulates before a multi-row insert
+		FlushMillis        int    `mapstructure:"flush_interval_ms"`             // Maximum delay in milliseconds before a partial batch is written
+		SpoolPath          string `mapstructure:"spool_path"`                    // Append-only file receiving click events when the channel is full; empty drops them
+		SpoolReplaySeconds int    `mapstructure:"spool_replay_interval_seconds"` // Interval between attempts to replay spooled events
+		RetryAttempts      int    `mapstructure:"retry_attempts"`                // Attempts to write a batch of clicks before dead-lettering it
+		RetryBackoffMillis int    `mapstructure:"retry_backoff_ms"`              // Delay before the first retry, doubled after each attempt
+		DeadLetterPath     string `mapstructure:"dead_letter_path"`              // File receiving clicks that still fail after retries; empty only logs them
 	} `mapstructure:"analytics"`
 
+	// GeoIP configuration for offline IP geolocation of clicks
+	GeoIP struct {
+		DatabasePath string `mapstructure:"database_path"` // Path to a MaxMind-format .mmdb file; empty disables geolocation
+	} `mapstructure:"geoip"`
+
 	// Monitor configuration for URL health checking
 	Monitor struct {
 		IntervalMinutes int `mapstructure:"interval_minutes"` // Interval in minutes between URL health checks
 	} `mapstructure:"monitor"`
+
+	// Auth configuration for the management API
+	Auth struct {
+		Enabled bool `mapstructure:"enabled"` // Whether /api/v1 requires an API key (redirects are always public)
+	} `mapstructure:"auth"`
+
+	// RateLimit configuration: token buckets per client (API key, or IP address without a key)
+	RateLimit struct {
+		Enabled  bool          `mapstructure:"enabled"`  // Whether link creation and redirects are rate limited
+		Create   RateLimitRule `mapstructure:"create"`   // Limit on POST /api/v1/links
+		Redirect RateLimitRule `mapstructure:"redirect"` // Limit on short URL redirects
+	} `mapstructure:"rate_limit"`
+
+	// Destination policy applied to the long URL of created and updated links (API and CLI)
+	Destination struct {
+		AllowedSchemes        []string `mapstructure:"allowed_schemes"`         // URL schemes accepted as destinations
+		BlocklistPath         string   `mapstructure:"blocklist_path"`          // File of rejected domains, one per line; empty disables the blocklist
+		AllowlistPath         string   `mapstructure:"allowlist_path"`          // File of the only accepted domains; empty (or an empty file) accepts all
+		ReloadIntervalSeconds int      `mapstructure:"reload_interval_seconds"` // Delay between checks of the list files for changes
+		BlockPrivateNetworks  bool     `mapstructure:"block_private_networks"`  // Reject localhost and private, loopback or link-local addresses
+		ResolveHosts          bool     `mapstructure:"resolve_hosts"`           // Also reject domains whose DNS records point to such addresses
+	} `mapstructure:"destination"`
+
+	// Normalization rules applied to the long URL of created and updated links before they are stored
+	Normalization struct {
+		Enabled             bool     `mapstructure:"enabled"`               // Whether long URLs are normalized at all (the original input is always kept)
+		LowercaseHost       bool     `mapstructure:"lowercase_host"`        // Lowercase the host name
+		StripDefaultPort    bool     `mapstructure:"strip_default_port"`    // Remove :80 from http and :443 from https URLs
+		CleanPath           bool     `mapstructure:"clean_path"`            // Resolve "." and ".." path segments
+		IDNToASCII          bool     `mapstructure:"idn_to_ascii"`          // Convert internationalized host names to punycode
+		StripTrackingParams bool     `mapstructure:"strip_tracking_params"` // Remove the query parameters listed in tracking_params
+		TrackingParams      []string `mapstructure:"tracking_params"`       // Tracking parameter names; a trailing '*' matches a prefix
+	} `mapstructure:"normalization"`
+
+	// Logging configuration for the structured application logger
+	Logging struct {
+		Level  string `mapstructure:"level"`  // Minimum level to output: debug, info, warn or error
+		Format string `mapstructure:"format"` // Output format: text (key=value) or json
+	} `mapstructure:"logging"`
+
+	// Metrics configuration for the Prometheus scrape endpoint
+	Metrics struct {
+		Enabled bool   `mapstructure:"enabled"` // Whether the server exposes metrics in the Prometheus text format
+		Path    string `mapstructure:"path"`    // Route of the scrape endpoint
+	} `mapstructure:"metrics"`
+}
+
+// RateLimitRule configures one token bucket rate limit.
+type RateLimitRule struct {
+	RequestsPerMinute int `mapstructure:"requests_per_minute"` // Sustained rate per client (0 = unlimited)
+	Burst             int `mapstructure:"burst"`               // Requests a client may send at once before being throttled
 }
 
 // LoadConfig loads the application configuration using Viper.
@@ -59,17 +142,64 @@ func LoadConfig() (*Config, error) {
 	// These will be used if no config file is found or if specific keys are missing
 	viper.SetDefault("server.port", 8080)
 	viper.SetDefault("server.base_url", "http://localhost:8080")
+	viper.SetDefault("server.redirect_status", 302)
+	viper.SetDefault("server.deduplicate_links", false)
+	viper.SetDefault("server.shutdown_timeout_seconds", 15)
+	viper.SetDefault("server.trusted_proxies", []string{"127.0.0.1", "::1"})
+	viper.SetDefault("database.driver", "sqlite")
+	viper.SetDefault("database.dsn", "")
 	viper.SetDefault("database.name", "url_shortener.db")
+	viper.SetDefault("database.max_open_conns", 0)
+	viper.SetDefault("database.max_idle_conns", 2)
+	viper.SetDefault("database.conn_max_lifetime_minutes", 0)
+	viper.SetDefault("cache.enabled", true)
+	viper.SetDefault("cache.size", 10000)
+	viper.SetDefault("cache.ttl_seconds", 60)
+	viper.SetDefault("cache.negative_ttl_seconds", 10)
 	viper.SetDefault("analytics.buffer_size", 1000)
 	viper.SetDefault("analytics.worker_count", 5)
+	viper.SetDefault("analytics.visitor_salt", "")
+	viper.SetDefault("analytics.batch_size", 100)
+	viper.SetDefault("analytics.flush_interval_ms", 1000)
+	viper.SetDefault("analytics.spool_path", "click_spool.jsonl")
+	viper.SetDefault("analytics.spool_replay_interval_seconds", 5)
+	viper.SetDefault("analytics.retry_attempts", 3)
+	viper.SetDefault("analytics.retry_backoff_ms", 200)
+	viper.SetDefault("analytics.dead_letter_path", "click_dead_letters.jsonl")
+	viper.SetDefault("geoip.database_path", "")
 	viper.SetDefault("monitor.interval_minutes", 5)
+	viper.SetDefault("auth.enabled", true)
+	viper.SetDefault("rate_limit.enabled", true)
+	viper.SetDefault("rate_limit.create.requests_per_minute", 30)
+	viper.SetDefault("rate_limit.create.burst", 10)
+	viper.SetDefault("rate_limit.redirect.requests_per_minute", 600)
+	viper.SetDefault("rate_limit.redirect.burst", 100)
+	viper.SetDefault("destination.allowed_schemes", []string{"http", "https"})
+	viper.SetDefault("destination.blocklist_path", "")
+	viper.SetDefault("destination.allowlist_path", "")
+	viper.SetDefault("destination.reload_interval_seconds", 10)
+	viper.SetDefault("destination.block_private_networks", true)
+	viper.SetDefault("destination.resolve_hosts", false)
+	viper.SetDefault("normalization.enabled", true)
+	viper.SetDefault("normalization.lowercase_host", true)
+	viper.SetDefault("normalization.strip_default_port", true)
+	viper.SetDefault("normalization.clean_path", true)
+	viper.SetDefault("normalization.idn_to_ascii", true)
+	viper.SetDefault("normalization.strip_tracking_params", false)
+	viper.SetDefault("normalization.tracking_params", []string{
+		"utm_*", "fbclid", "gclid", "dclid", "msclkid", "yclid", "mc_cid", "mc_eid", "igshid", "_hsenc", "_hsmi",
+	})
+	viper.SetDefault("logging.level", "info")
+	viper.SetDefault("logging.format", "text")
+	viper.SetDefault("metrics.enabled", true)
+	viper.SetDefault("metrics.path", "/metrics")
 
 	// Attempt to read the config file
 	if err := viper.ReadInConfig(); err != nil {
 		// Check if the error is specifically "config file not found"
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
 			// This is not a fatal error - we'll use default values
-			log.Println("Config file not found, using default values")
+			slog.Info("Config file not found, using default values")
 		} else {
 			// Any other error (permissions, malformed YAML, etc.) is fatal
 			return nil, fmt.Errorf("error reading config file: %w", err)
@@ -84,8 +214,12 @@ func LoadConfig() (*Config, error) {
 	}
 
 	// Log the loaded configuration for debugging and verification purposes
-	log.Printf("Configuration loaded: Server Port=%d, DB Name=%s, Analytics Buffer=%d, Monitor Interval=%dmin",
-		cfg.Server.Port, cfg.Database.Name, cfg.Analytics.BufferSize, cfg.Monitor.IntervalMinutes)
+	slog.Debug("Configuration loaded",
+		"server_port", cfg.Server.Port,
+		"db_driver", cfg.Database.Driver,
+		"db_name", cfg.Database.Name,
+		"analytics_buffer", cfg.Analytics.BufferSize,
+		"monitor_interval_minutes", cfg.Monitor.IntervalMinutes)
 
 	// Return the successfully loaded and parsed configuration
 	return &cfg, nil
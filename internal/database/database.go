@@ -0,0 +1,156 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/urlnorm"
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Supported values for the database.driver configuration key.
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// Open connects to the database described by the configuration and applies the connection-pool settings.
+// This is the single connection factory used by every command (run-server, create, stats, migrate...),
+// so switching backends only requires a configuration change.
+// Parameters:
+//   - cfg: application configuration; only the database section is used
+//
+// Returns:
+//   - *gorm.DB: ready-to-use GORM connection; close it via db.DB() when done
+//   - error: if the driver is unknown or the connection cannot be established
+func Open(cfg *config.Config) (*gorm.DB, error) {
+	dialector, err := newDialector(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	// TranslateError turns driver-specific unique violations into gorm.ErrDuplicatedKey,
+	// so concurrent inserts of the same short code can be told apart from real failures
+	db, err := gorm.Open(dialector, &gorm.Config{TranslateError: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s database: %w", cfg.Database.Driver, err)
+	}
+
+	// Apply connection-pool settings on the underlying database/sql pool
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get underlying SQL database: %w", err)
+	}
+	if cfg.Database.MaxOpenConns > 0 {
+		sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	}
+	if cfg.Database.MaxIdleConns > 0 {
+		sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	}
+	if cfg.Database.ConnMaxLifetimeMinutes > 0 {
+		sqlDB.SetConnMaxLifetime(time.Duration(cfg.Database.ConnMaxLifetimeMinutes) * time.Minute)
+	}
+
+	return db, nil
+}
+
+// AutoMigrate creates or updates the tables of every model of the application.
+// Keeping the model list here guarantees that 'migrate' and 'run-server' always migrate the same schema.
+// It also guarantees that the default workspace exists, since links and keys created without
+// an explicit workspace belong to it.
+func AutoMigrate(db *gorm.DB) error {
+	// Short codes used to be unique across the whole table; they are now unique per namespace
+	// (idx_links_namespace_short_code), so the old single-column index must go
+	if db.Migrator().HasIndex(&models.Link{}, legacyShortCodeIndex) {
+		if err := db.Migrator().DropIndex(&models.Link{}, legacyShortCodeIndex); err != nil {
+			return fmt.Errorf("failed to drop index %s: %w", legacyShortCodeIndex, err)
+		}
+	}
+
+	if err := db.AutoMigrate(&models.Workspace{}, &models.Link{}, &models.Click{}, &models.APIKey{}); err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	defaultWorkspace := models.Workspace{Slug: models.DefaultWorkspace, Name: "Default workspace"}
+	if err := db.Where("slug = ?", models.DefaultWorkspace).FirstOrCreate(&defaultWorkspace).Error; err != nil {
+		return fmt.Errorf("failed to create the default workspace: %w", err)
+	}
+	if err := backfillDomains(db); err != nil {
+		return err
+	}
+	return backfillLongURLHashes(db)
+}
+
+// backfillDomains fills the domain column of links created before the domain filter existed,
+// so the link listing can filter them by destination host like new links.
+// Soft-deleted links are included so that a restored row never lacks its domain.
+func backfillDomains(db *gorm.DB) error {
+	var links []models.Link
+	err := db.Unscoped().Select("id", "long_url").Where("domain = ? OR domain IS NULL", "").
+		FindInBatches(&links, 500, func(_ *gorm.DB, _ int) error {
+			for _, link := range links {
+				domain := models.DomainOf(link.LongURL)
+				if domain == "" {
+					// Unparsable destinations keep an empty domain and are simply skipped on the next run
+					continue
+				}
+				if err := db.Unscoped().Model(&models.Link{}).Where("id = ?", link.ID).
+					UpdateColumn("domain", domain).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to compute link domains: %w", err)
+	}
+	return nil
+}
+
+// backfillLongURLHashes computes the long URL hash of links created before deduplication existed,
+// so their destinations can be found by the deduplication lookup like those of new links.
+// Soft-deleted links are included so that a restored row never lacks its hash.
+func backfillLongURLHashes(db *gorm.DB) error {
+	var links []models.Link
+	err := db.Unscoped().Select("id", "long_url").Where("long_url_hash = ?", "").
+		FindInBatches(&links, 500, func(_ *gorm.DB, _ int) error {
+			for _, link := range links {
+				if err := db.Unscoped().Model(&models.Link{}).Where("id = ?", link.ID).
+					UpdateColumn("long_url_hash", urlnorm.Hash(link.LongURL)).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		}).Error
+	if err != nil {
+		return fmt.Errorf("failed to compute long URL hashes: %w", err)
+	}
+	return nil
+}
+
+// legacyShortCodeIndex is the unique index on links.short_code created before workspaces existed.
+const legacyShortCodeIndex = "idx_links_short_code"
+
+// newDialector builds the GORM dialector matching the configured driver.
+// For SQLite, the DSN defaults to the database file name (database.name) for backward compatibility.
+func newDialector(cfg *config.Config) (gorm.Dialector, error) {
+	switch cfg.Database.Driver {
+	case DriverSQLite, "":
+		dsn := cfg.Database.DSN
+		if dsn == "" {
+			dsn = cfg.Database.Name
+		}
+		return sqlite.Open(dsn), nil
+	case DriverPostgres:
+		if cfg.Database.DSN == "" {
+			return nil, fmt.Errorf("database.dsn is required for the %s driver", DriverPostgres)
+		}
+		return postgres.Open(cfg.Database.DSN), nil
+	}
+	return nil, fmt.Errorf("unsupported database driver '%s' (expected '%s' or '%s')",
+		cfg.Database.Driver, DriverSQLite, DriverPostgres)
+}
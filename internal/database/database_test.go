@@ -0,0 +1,227 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/urlnorm"
+	"gorm.io/gorm"
+)
+
+// openTestDB opens a private in-memory SQLite database without migrating it.
+// A single connection is used because every new connection to ":memory:" would see an empty database.
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Database.Driver = DriverSQLite
+	cfg.Database.DSN = ":memory:"
+	cfg.Database.MaxOpenConns = 1
+	return openConfigured(t, cfg)
+}
+
+// openConfigured opens the database described by cfg and closes it at the end of the test.
+func openConfigured(t *testing.T, cfg *config.Config) *gorm.DB {
+	t.Helper()
+	db, err := Open(cfg)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestOpenRejectsInvalidConfiguration(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Database.Driver = "mysql"
+	if _, err := Open(cfg); err == nil {
+		t.Error("expected an error for an unsupported driver")
+	}
+
+	cfg.Database.Driver = DriverPostgres
+	if _, err := Open(cfg); err == nil {
+		t.Error("expected an error for postgres without a DSN")
+	}
+}
+
+func TestOpenSQLiteFallsBackToDatabaseName(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Database.Name = t.TempDir() + "/test.db"
+	db := openConfigured(t, cfg)
+	if err := AutoMigrate(db); err != nil {
+		t.Fatalf("AutoMigrate failed: %v", err)
+	}
+	if _, err := os.Stat(cfg.Database.Name); err != nil {
+		t.Fatalf("database file was not created at database.name: %v", err)
+	}
+}
+
+func TestAutoMigrateCreatesDefaultWorkspaceOnce(t *testing.T) {
+	db := openTestDB(t)
+	for i := 0; i < 2; i++ {
+		if err := AutoMigrate(db); err != nil {
+			t.Fatalf("AutoMigrate run %d failed: %v", i+1, err)
+		}
+	}
+
+	var count int64
+	if err := db.Model(&models.Workspace{}).Where("slug = ?", models.DefaultWorkspace).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected exactly one default workspace, got %d", count)
+	}
+}
+
+func TestAutoMigrateUpgradesLegacyLinks(t *testing.T) {
+	db := openTestDB(t)
+	if err := AutoMigrate(db); err != nil {
+		t.Fatalf("AutoMigrate failed: %v", err)
+	}
+
+	// Recreate the state of a database from before workspaces, domains and hashes:
+	// a table-wide unique short code index and rows missing the computed columns
+	if err := db.Exec("CREATE UNIQUE INDEX " + legacyShortCodeIndex + " ON links (short_code)").Error; err != nil {
+		t.Fatal(err)
+	}
+	legacyURL := "HTTPS://WWW.Example.com/Page"
+	if err := db.Exec("INSERT INTO links (short_code, long_url, created_at, updated_at) VALUES (?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)",
+		"legacy", legacyURL).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Exec("INSERT INTO links (short_code, long_url, created_at, updated_at, deleted_at) VALUES (?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)",
+		"gone", "https://deleted.example.org/").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := AutoMigrate(db); err != nil {
+		t.Fatalf("AutoMigrate on legacy data failed: %v", err)
+	}
+
+	if db.Migrator().HasIndex(&models.Link{}, legacyShortCodeIndex) {
+		t.Errorf("legacy index %s was not dropped", legacyShortCodeIndex)
+	}
+
+	var links []models.Link
+	if err := db.Unscoped().Order("id").Find(&links).Error; err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]struct{ domain, hash string }{
+		"legacy": {"www.example.com", urlnorm.Hash(legacyURL)},
+		"gone":   {"deleted.example.org", urlnorm.Hash("https://deleted.example.org/")},
+	}
+	for _, link := range links {
+		expected := want[link.ShortCode]
+		if link.Domain != expected.domain {
+			t.Errorf("%s: domain %q, want %q", link.ShortCode, link.Domain, expected.domain)
+		}
+		if link.LongURLHash != expected.hash {
+			t.Errorf("%s: long_url_hash %q, want %q", link.ShortCode, link.LongURLHash, expected.hash)
+		}
+		if link.HealthStatus != models.HealthUnknown {
+			t.Errorf("%s: health_status %q, want %q", link.ShortCode, link.HealthStatus, models.HealthUnknown)
+		}
+	}
+
+	// Without the legacy index, the same short code can be used in another namespace
+	namespaced := models.Link{ShortCode: "legacy", Namespace: "acme", Workspace: "acme", LongURL: "https://example.com"}
+	if err := db.Create(&namespaced).Error; err != nil {
+		t.Errorf("short code could not be reused in another namespace: %v", err)
+	}
+}
+
+func TestOpenTranslatesDuplicateKeys(t *testing.T) {
+	db := openTestDB(t)
+	if err := AutoMigrate(db); err != nil {
+		t.Fatalf("AutoMigrate failed: %v", err)
+	}
+
+	first := models.Link{ShortCode: "abc123", LongURL: "https://example.com"}
+	if err := db.Create(&first).Error; err != nil {
+		t.Fatal(err)
+	}
+	second := models.Link{ShortCode: "abc123", LongURL: "https://example.org"}
+	if err := db.Create(&second).Error; !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Fatalf("expected gorm.ErrDuplicatedKey, got %v", err)
+	}
+}
+
+func TestClickFieldsFitTheirColumns(t *testing.T) {
+	db := openTestDB(t)
+	if err := AutoMigrate(db); err != nil {
+		t.Fatalf("AutoMigrate failed: %v", err)
+	}
+	checkLongClickFields(t, db)
+}
+
+// checkLongClickFields inserts a click with over-long client-controlled fields, as a hostile
+// client can send them, and checks that it is stored cut to the size of its columns.
+// SQLite would store the full values; the check matters on PostgreSQL, which rejects them.
+func checkLongClickFields(t *testing.T, db *gorm.DB) {
+	t.Helper()
+	// A unique code, since the PostgreSQL test database may keep the rows of earlier runs
+	link := models.Link{ShortCode: fmt.Sprintf("longua%d", time.Now().UnixNano()), LongURL: "https://example.com"}
+	if err := db.Create(&link).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	click := models.Click{
+		LinkID:    link.ID,
+		Timestamp: time.Now().UTC(),
+		UserAgent: strings.Repeat("Mozilla/5.0 é ", 100) + "\xff",
+		IPAddress: strings.Repeat("1", 60),
+		Referrer:  strings.Repeat("a", 300) + ".example",
+	}
+	click.FitColumns()
+	if err := db.Create(&click).Error; err != nil {
+		t.Fatalf("click with a long user agent was not stored: %v", err)
+	}
+
+	var stored models.Click
+	if err := db.First(&stored, click.ID).Error; err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []struct {
+		name  string
+		value string
+		size  int
+	}{
+		{"user_agent", stored.UserAgent, 255},
+		{"ip_address", stored.IPAddress, 50},
+		{"referrer", stored.Referrer, 255},
+	} {
+		if !utf8.ValidString(field.value) || utf8.RuneCountInString(field.value) != field.size {
+			t.Errorf("%s: stored %d characters (valid UTF-8: %v), want %d",
+				field.name, utf8.RuneCountInString(field.value), utf8.ValidString(field.value), field.size)
+		}
+	}
+}
+
+// TestPostgres runs the migrations against a real PostgreSQL server when
+// URLSHORTENER_TEST_POSTGRES_DSN is set (e.g. in CI with a postgres service container).
+func TestPostgres(t *testing.T) {
+	dsn := os.Getenv("URLSHORTENER_TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("URLSHORTENER_TEST_POSTGRES_DSN not set")
+	}
+	cfg := &config.Config{}
+	cfg.Database.Driver = DriverPostgres
+	cfg.Database.DSN = dsn
+	db := openConfigured(t, cfg)
+	for i := 0; i < 2; i++ {
+		if err := AutoMigrate(db); err != nil {
+			t.Fatalf("AutoMigrate run %d failed: %v", i+1, err)
+		}
+	}
+	checkLongClickFields(t, db)
+}
@@ -51,3 +51,56 @@ func (e ErrConfigLoad) Error() string {
 
 // ErrInvalidShortCode is returned when the short code format is invalid
 var ErrInvalidShortCode = errors.New("invalid short code format")
+
+// ErrShortCodeReserved is returned when a custom alias collides with a reserved word (e.g. "api", "health")
+var ErrShortCodeReserved = errors.New("short code is reserved")
+
+// ErrShortCodeTaken is returned when a custom alias is already used by another link
+var ErrShortCodeTaken = errors.New("short code already in use")
+
+// ErrLinkExpired is returned when a link is past its expiration date or has used up its click budget
+var ErrLinkExpired = errors.New("link has expired")
+
+// ErrLinkDisabled is returned when a link has been disabled by its owner
+var ErrLinkDisabled = errors.New("link is disabled")
+
+// ErrInvalidExpiration is returned when the requested expiration date or click budget is invalid
+var ErrInvalidExpiration = errors.New("invalid link expiration")
+
+// ErrInvalidListQuery is returned when a link listing has an invalid cursor, sort key or filter
+var ErrInvalidListQuery = errors.New("invalid list query")
+
+// ErrInvalidTimeRange is returned when a time-series query has an invalid granularity or date range
+var ErrInvalidTimeRange = errors.New("invalid time range")
+
+// ErrInvalidRedirectStatus is returned when a redirect status is not one of 301, 302, 307 or 308
+var ErrInvalidRedirectStatus = errors.New("invalid redirect status")
+
+// ErrInvalidAPIKey is returned when a request carries no API key, an unknown one or a revoked one
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// ErrAPIKeyNotFound is returned when an API key ID does not exist
+var ErrAPIKeyNotFound = errors.New("API key not found")
+
+// ErrInvalidScope is returned when an API key is created with an unknown scope or without any scope
+var ErrInvalidScope = errors.New("invalid API key scope")
+
+// ErrWorkspaceNotFound is returned when a workspace slug does not match any workspace
+var ErrWorkspaceNotFound = errors.New("workspace not found")
+
+// ErrInvalidWorkspace is returned when a workspace slug is malformed or reserved
+var ErrInvalidWorkspace = errors.New("invalid workspace")
+
+// ErrWorkspaceTaken is returned when creating a workspace whose slug is already used
+var ErrWorkspaceTaken = errors.New("workspace already exists")
+
+// ErrDestinationBlocked is returned when the destination of a link is rejected by the destination policy
+// (disallowed scheme, blocklisted or non-allowlisted domain, private or loopback address...)
+type ErrDestinationBlocked struct {
+	URL    string
+	Reason string
+}
+
+func (e ErrDestinationBlocked) Error() string {
+	return fmt.Sprintf("destination %s is not allowed: %s", e.URL, e.Reason)
+}
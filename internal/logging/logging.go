@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Supported log output formats (logging.format in the configuration).
+const (
+	FormatText = "text" // Human-readable key=value lines
+	FormatJSON = "json" // One JSON object per line, for log collectors
+)
+
+// requestIDKey is the context key under which the request ID of an HTTP request is stored.
+type requestIDKey struct{}
+
+// Setup builds the application logger and installs it as the slog default.
+// Installing it as the default also routes the standard library 'log' package through it,
+// so messages from code still using log.Printf (or log.Fatalf) share the same format.
+// Parameters:
+//   - level: minimum level to output ("debug", "info", "warn" or "error")
+//   - format: FormatText or FormatJSON
+//   - w: destination of the log lines (usually os.Stderr)
+//
+// Returns:
+//   - *slog.Logger: the configured logger
+//   - error: if the level or the format is not recognized
+func Setup(level, format string, w io.Writer) (*slog.Logger, error) {
+	lvl, err := ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	var handler slog.Handler
+	switch strings.ToLower(strings.TrimSpace(format)) {
+	case FormatText, "":
+		handler = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		return nil, fmt.Errorf("unsupported log format '%s' (expected %s or %s)", format, FormatText, FormatJSON)
+	}
+
+	logger := slog.New(handler)
+	slog.SetDefault(logger)
+	return logger, nil
+}
+
+// ParseLevel converts a level name from the configuration into a slog.Level.
+// An empty name means "info".
+func ParseLevel(level string) (slog.Level, error) {
+	var lvl slog.Level
+	if strings.TrimSpace(level) == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := lvl.UnmarshalText([]byte(strings.TrimSpace(level))); err != nil {
+		return slog.LevelInfo, fmt.Errorf("unsupported log level '%s' (expected debug, info, warn or error)", level)
+	}
+	return lvl, nil
+}
+
+// WithRequestID returns a copy of ctx carrying the given request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestID returns the request ID carried by ctx, or an empty string outside of an HTTP request.
+func RequestID(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// FromContext returns the default logger, annotated with the request ID carried by ctx if any.
+// Services and handlers log through it so every line of a request can be correlated.
+func FromContext(ctx context.Context) *slog.Logger {
+	if requestID := RequestID(ctx); requestID != "" {
+		return slog.Default().With("request_id", requestID)
+	}
+	return slog.Default()
+}
@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric name exposed by the application.
+const namespace = "urlshortener"
+
+// registry holds the application collectors. A dedicated registry (rather than the global default)
+// keeps /metrics limited to what this package declares plus the Go runtime and process metrics.
+var registry = prometheus.NewRegistry()
+
+var (
+	// redirects counts redirect requests by HTTP status (30x, 403, 404, 410, 500)
+	redirects = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "redirects_total",
+		Help:      "Number of short URL resolutions, by HTTP status.",
+	}, []string{"status"})
+
+	// redirectDuration measures the time spent answering redirect requests by HTTP status
+	redirectDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "redirect_duration_seconds",
+		Help:      "Latency of short URL resolutions, by HTTP status.",
+		Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+	}, []string{"status"})
+
+	// linksCreated counts successfully created links
+	linksCreated = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "links_created_total",
+		Help:      "Number of short links created.",
+	})
+
+	// clickWriteDuration measures the time of each batch insert of clicks
+	clickWriteDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "click_write_duration_seconds",
+		Help:      "Latency of click batch flushes by the workers, retries included.",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	// clicksPersisted counts clicks written to the database by the workers
+	clicksPersisted = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "clicks_persisted_total",
+		Help:      "Number of clicks written to the database by the workers.",
+	})
+
+	// clickWriteErrors counts failed click write attempts
+	clickWriteErrors = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "click_write_errors_total",
+		Help:      "Number of failed click write attempts (each retry and fallback insert counts).",
+	})
+
+	// clicksDeadLettered counts clicks that still failed after all retries
+	clicksDeadLettered = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "clicks_dead_lettered_total",
+		Help:      "Number of clicks that could not be written after all retries (dead-lettered or lost).",
+	})
+
+	// monitorChecks counts URL monitor checks by result (up or down)
+	monitorChecks = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "monitor_checks_total",
+		Help:      "Number of destination URL health checks, by result.",
+	}, []string{"result"})
+
+	// rateLimited counts requests rejected with 429 by rule (create or redirect)
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "Number of requests rejected by the rate limiter, by rule.",
+	}, []string{"rule"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		redirects, redirectDuration, linksCreated,
+		clickWriteDuration, clicksPersisted, clickWriteErrors, clicksDeadLettered,
+		monitorChecks, rateLimited,
+	)
+}
+
+// Handler returns the HTTP handler serving all registered metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRedirect records one redirect request and its latency.
+// Parameters:
+//   - status: HTTP status returned to the client
+//   - duration: time spent handling the request
+func ObserveRedirect(status int, duration time.Duration) {
+	label := strconv.Itoa(status)
+	redirects.WithLabelValues(label).Inc()
+	redirectDuration.WithLabelValues(label).Observe(duration.Seconds())
+}
+
+// LinkCreated records the creation of a link.
+func LinkCreated() {
+	linksCreated.Inc()
+}
+
+// ObserveClickWrite records one batch of clicks flushed by a worker.
+// Parameters:
+//   - duration: time spent writing the batch, retries and fallback inserts included
+//   - persisted: number of clicks actually written
+func ObserveClickWrite(duration time.Duration, persisted int) {
+	clickWriteDuration.Observe(duration.Seconds())
+	clicksPersisted.Add(float64(persisted))
+}
+
+// ClickWriteFailed records one failed click write attempt.
+func ClickWriteFailed() {
+	clickWriteErrors.Inc()
+}
+
+// ClicksDeadLettered records clicks that still failed after every retry.
+func ClicksDeadLettered(count int) {
+	clicksDeadLettered.Add(float64(count))
+}
+
+// MonitorCheck records the result of a URL monitor check.
+// Parameters:
+//   - result: the new health status of the link (models.HealthUp or models.HealthDown)
+func MonitorCheck(result string) {
+	monitorChecks.WithLabelValues(result).Inc()
+}
+
+// RateLimited records a request rejected by the rate limiter.
+// Parameters:
+//   - rule: the name of the rule that rejected it ("create" or "redirect")
+func RateLimited(rule string) {
+	rateLimited.WithLabelValues(rule).Inc()
+}
+
+// RegisterGaugeFunc exposes a value read at scrape time, such as the depth of the click channel.
+// Parameters:
+//   - name: metric name without the namespace prefix
+//   - help: metric description
+//   - value: function returning the current value
+func RegisterGaugeFunc(name, help string, value func() float64) {
+	registry.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      help,
+	}, value))
+}
+
+// RegisterCounterFunc exposes a monotonically increasing value maintained elsewhere,
+// such as the counters of the click spool or of the short-code cache.
+// Parameters:
+//   - name: metric name without the namespace prefix (should end in _total)
+//   - help: metric description
+//   - value: function returning the current count
+func RegisterCounterFunc(name, help string, value func() float64) {
+	registry.MustRegister(prometheus.NewCounterFunc(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      name,
+		Help:      help,
+	}, value))
+}
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// Scopes that can be granted to an API key.
+const (
+	ScopeCreate    = "create"     // Create links (POST /api/v1/links)
+	ScopeReadStats = "read-stats" // List links and read their statistics
+	ScopeAdmin     = "admin"      // Manage existing links (update, disable, delete); implies every other scope
+)
+
+// APIKey is a credential allowing a client to call the management API (/api/v1).
+// Only a SHA-256 hash of the key is stored: the plain key is shown once at creation and cannot be recovered.
+type APIKey struct {
+	// ID is the primary key with auto-increment functionality
+	ID uint `gorm:"primaryKey"`
+
+	// Name is a human-readable label identifying who or what uses the key (e.g. "ci-pipeline")
+	Name string `gorm:"size:100;not null"`
+
+	// Prefix is the beginning of the plain key, kept in clear so a key can be recognized in listings
+	Prefix string `gorm:"size:16;not null"`
+
+	// KeyHash is the hex-encoded SHA-256 hash of the plain key
+	// - uniqueIndex: keys are looked up by hash on every authenticated request
+	KeyHash string `gorm:"uniqueIndex;size:64;not null"`
+
+	// Workspace is the slug of the workspace the key acts on
+	// - every request authenticated by the key only sees and creates links of this workspace
+	Workspace string `gorm:"size:32;not null;default:'default';index"`
+
+	// Scopes is the comma-separated list of granted scopes (e.g. "create,read-stats")
+	Scopes string `gorm:"size:255;not null"`
+
+	// CreatedAt automatically stores the timestamp when the record is created
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+
+	// LastUsedAt is the last time the key authenticated a request (refreshed at most once a minute)
+	// - nil means the key has never been used
+	LastUsedAt *time.Time
+
+	// RevokedAt is the moment the key was revoked
+	// - nil means the key is active; revoked keys are kept so listings show their history
+	RevokedAt *time.Time
+}
+
+// ScopeList returns the granted scopes as a slice.
+func (k *APIKey) ScopeList() []string {
+	if k.Scopes == "" {
+		return nil
+	}
+	return strings.Split(k.Scopes, ",")
+}
+
+// HasScope reports whether the key grants the given scope. The admin scope grants every scope.
+func (k *APIKey) HasScope(scope string) bool {
+	for _, granted := range k.ScopeList() {
+		if granted == scope || granted == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// Revoked reports whether the key has been revoked.
+func (k *APIKey) Revoked() bool {
+	return k.RevokedAt != nil
+}
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+)
 
 // Click represents a click event on a shortened URL stored in the database.
 // This model tracks user interactions for analytics and statistics purposes.
@@ -22,14 +26,67 @@ type Click struct {
 	Timestamp time.Time
 
 	// UserAgent stores the browser/client information from the HTTP request
-	// - size:255: limits the database column to 255 characters
+	// - size:255: limits the database column to 255 characters (see FitColumns)
 	// Useful for analytics: browser type, mobile vs desktop, OS information
 	UserAgent string `gorm:"size:255"`
 
 	// IPAddress stores the IP address of the user who clicked the link
-	// - size:50: sufficient for both IPv4 and IPv6 addresses
+	// - size:50: sufficient for both IPv4 and IPv6 addresses (see FitColumns)
 	// Used for geographical analytics and potential abuse detection
 	IPAddress string `gorm:"size:50"`
+
+	// Country is the ISO 3166-1 alpha-2 code resolved from IPAddress (e.g. "FR")
+	// - empty when geolocation is disabled or the IP is unknown (private, loopback...)
+	Country string `gorm:"size:2"`
+
+	// Region is the first-level subdivision resolved from IPAddress (e.g. "Île-de-France")
+	// - only filled when the configured database is a City database
+	Region string `gorm:"size:100"`
+
+	// Referrer is the host of the page the click came from (e.g. "twitter.com")
+	// - normalized from the Referer header: lowercased, without scheme, path or "www." prefix
+	// - empty for direct traffic (no Referer header)
+	// - size:255: see FitColumns
+	Referrer string `gorm:"size:255"`
+
+	// VisitorHash identifies the visitor for unique-visitor counting without storing who they are
+	// - salted SHA-256 of IP + user agent + UTC day, truncated to 32 hex characters
+	// - the same person gets a new hash every day, and the raw IP is not needed to count visitors
+	// - index: speeds up COUNT(DISTINCT visitor_hash) per link
+	VisitorHash string `gorm:"size:32;index"`
+}
+
+// Sizes of the Click columns filled from client-controlled request data.
+const (
+	maxUserAgentLength = 255
+	maxIPAddressLength = 50
+	maxReferrerLength  = 255
+)
+
+// FitColumns shortens the client-controlled fields to the size of their column.
+// PostgreSQL rejects a value longer than a varchar column (or not valid UTF-8) and fails
+// the whole batch insert with it, where SQLite stores anything, so every click must go
+// through this before being written. Lengths are counted in characters, like varchar(n).
+func (c *Click) FitColumns() {
+	c.UserAgent = fitColumn(c.UserAgent, maxUserAgentLength)
+	c.IPAddress = fitColumn(c.IPAddress, maxIPAddressLength)
+	c.Referrer = fitColumn(c.Referrer, maxReferrerLength)
+}
+
+// fitColumn replaces invalid UTF-8 sequences in value and cuts it to at most size characters.
+func fitColumn(value string, size int) string {
+	value = strings.ToValidUTF8(value, string(utf8.RuneError))
+	if utf8.RuneCountInString(value) <= size {
+		return value
+	}
+	count := 0
+	for i := range value {
+		if count == size {
+			return value[:i]
+		}
+		count++
+	}
+	return value
 }
 
 // ClickEvent represents a raw click event intended to be passed through channels.
@@ -40,4 +97,6 @@ type ClickEvent struct {
 	Timestamp time.Time // When the click occurred
 	UserAgent string    // Browser/client information
 	IPAddress string    // User's IP address
+	Referrer  string    // Normalized referrer host, empty for direct traffic
+	RequestID string    // ID of the HTTP request that produced the click, for log correlation (not stored)
 }
@@ -1,6 +1,19 @@
 package models
 
-import "time"
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Health statuses recorded by the URL monitor for each link's long URL.
+const (
+	HealthUnknown = "unknown" // The monitor has not checked the URL yet
+	HealthUp      = "up"      // The last check got a 2xx/3xx response
+	HealthDown    = "down"    // The last check failed or got a 4xx/5xx response
+)
 
 // Link represents a shortened URL link stored in the database.
 // This struct uses GORM tags to define database schema and constraints.
@@ -8,17 +21,149 @@ type Link struct {
 	// ID is the primary key with auto-increment functionality
 	ID uint `gorm:"primaryKey"`
 
-	// ShortCode is the unique identifier for the shortened URL (e.g., "abc123")
-	// - uniqueIndex: ensures no duplicate short codes can exist
-	// - size:10: limits the column size to 10 characters in the database
+	// ShortCode is the identifier for the shortened URL (e.g., "abc123")
+	// - uniqueIndex: no two links share a short code within the same namespace
+	// - size:32: large enough for both generated codes (6 chars) and custom aliases (up to 32 chars)
 	// - not null: prevents empty short codes
-	ShortCode string `gorm:"uniqueIndex;size:10;not null"`
+	ShortCode string `gorm:"uniqueIndex:idx_links_namespace_short_code,priority:2;size:32;not null"`
+
+	// Workspace is the slug of the workspace owning the link (see Workspace)
+	// - index: listings and statistics are always filtered by workspace
+	// - idx_links_workspace_long_url_hash: finds the links of a workspace sharing a destination (deduplication)
+	Workspace string `gorm:"size:32;not null;default:'default';index;index:idx_links_workspace_long_url_hash,priority:1"`
+
+	// Namespace scopes the uniqueness of ShortCode
+	// - empty for links of regular workspaces, which share the global namespace and redirect from /<code>
+	// - the workspace slug for links of namespaced workspaces, which redirect from /<workspace>/<code>
+	Namespace string `gorm:"uniqueIndex:idx_links_namespace_short_code,priority:1;size:32;not null;default:''"`
 
-	// LongURL stores the original URL that the short code redirects to
+	// LongURL stores the URL that the short code redirects to, in its normalized form (see urlnorm.Normalizer)
 	// - not null: ensures every link has a destination URL
 	LongURL string `gorm:"not null"`
 
+	// OriginalURL is the destination exactly as submitted, kept for display
+	// - empty for links created before normalization existed (DisplayURL then falls back to LongURL)
+	OriginalURL string `gorm:"not null;default:''"`
+
+	// LongURLHash is the SHA-256 of the normalized LongURL (see urlnorm.Hash)
+	// - indexed with Workspace so an identical destination is found without comparing every URL
+	// - not unique: deduplication is optional and several links may share a destination
+	LongURLHash string `gorm:"size:64;not null;default:'';index:idx_links_workspace_long_url_hash,priority:2"`
+
+	// Domain is the lowercased host of LongURL (e.g. "www.example.com")
+	// - index: allows filtering links by destination domain without scanning every URL
+	Domain string `gorm:"size:255;index"`
+
 	// CreatedAt automatically stores the timestamp when the record is created
 	// - autoCreateTime: GORM automatically sets this field when inserting
 	CreatedAt time.Time `gorm:"autoCreateTime"`
+
+	// ExpiresAt is the optional moment after which the link stops redirecting
+	// - nil means the link never expires by date
+	ExpiresAt *time.Time
+
+	// MaxClicks is the optional click budget of the link
+	// - 0 means unlimited; once the recorded clicks reach this value the link is expired
+	MaxClicks int `gorm:"not null;default:0"`
+
+	// RedirectStatus is the HTTP status used when redirecting (301, 302, 307 or 308)
+	// - 0 means "use the server default" (server.redirect_status), so existing links follow config changes
+	RedirectStatus int `gorm:"not null;default:0"`
+
+	// Disabled temporarily turns the link off without deleting it
+	// - a disabled link keeps its short code and click history but stops redirecting
+	Disabled bool `gorm:"not null;default:false"`
+
+	// UpdatedAt automatically stores the timestamp of the last modification
+	// - autoUpdateTime: GORM automatically refreshes this field on every update
+	UpdatedAt time.Time `gorm:"autoUpdateTime"`
+
+	// DeletedAt enables GORM soft deletes
+	// - deleted links are hidden from normal queries but their rows (and clicks) remain in the database
+	// - index: speeds up the "deleted_at IS NULL" filter GORM adds to every query
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+
+	// HealthStatus is the last accessibility state seen by the URL monitor (HealthUnknown, HealthUp or HealthDown)
+	// - index: allows listing only broken or healthy links
+	HealthStatus string `gorm:"size:16;not null;default:'unknown';index"`
+
+	// LastCheckedAt is the time of the last monitor check, nil if never checked
+	LastCheckedAt *time.Time
+}
+
+// Path returns the path of the short URL relative to the base URL: "<code>" or "<namespace>/<code>".
+func (l *Link) Path() string {
+	if l.Namespace == "" {
+		return l.ShortCode
+	}
+	return l.Namespace + "/" + l.ShortCode
+}
+
+// DisplayURL returns the destination as its owner typed it, or LongURL for links without a recorded original.
+func (l *Link) DisplayURL() string {
+	if l.OriginalURL != "" {
+		return l.OriginalURL
+	}
+	return l.LongURL
+}
+
+// DomainOf returns the value of Link.Domain for a destination: its lowercased host without port,
+// or an empty string if the URL cannot be parsed.
+func DomainOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(parsed.Hostname())
+}
+
+// IsExpired reports whether the link is past its expiration date or has used up its click budget.
+// Parameters:
+//   - now: the reference time to compare ExpiresAt against
+//   - totalClicks: the number of clicks recorded so far for this link
+func (l *Link) IsExpired(now time.Time, totalClicks int) bool {
+	if l.ExpiresAt != nil && !now.Before(*l.ExpiresAt) {
+		return true
+	}
+	return l.MaxClicks > 0 && totalClicks >= l.MaxClicks
+}
+
+// CanExpire reports whether the link has an expiration date or a click budget.
+func (l *Link) CanExpire() bool {
+	return l.ExpiresAt != nil || l.MaxClicks > 0
+}
+
+// RemainingLifetime returns how long the link stays valid, or nil if it has no expiration date.
+// The returned duration is never negative.
+func (l *Link) RemainingLifetime(now time.Time) *time.Duration {
+	if l.ExpiresAt == nil {
+		return nil
+	}
+	remaining := l.ExpiresAt.Sub(now)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &remaining
+}
+
+// RemainingClicks returns how many clicks are left in the budget, or nil if the budget is unlimited.
+// The returned value is never negative.
+func (l *Link) RemainingClicks(totalClicks int) *int {
+	if l.MaxClicks <= 0 {
+		return nil
+	}
+	remaining := l.MaxClicks - totalClicks
+	if remaining < 0 {
+		remaining = 0
+	}
+	return &remaining
+}
+
+// EffectiveRedirectStatus returns the status to redirect with: the link's own status,
+// or the given server default when the link does not define one.
+func (l *Link) EffectiveRedirectStatus(defaultStatus int) int {
+	if l.RedirectStatus != 0 {
+		return l.RedirectStatus
+	}
+	return defaultStatus
 }
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// DefaultWorkspace is the slug of the workspace created automatically.
+// Links and API keys created before workspaces existed, or without an explicit workspace, belong to it.
+const DefaultWorkspace = "default"
+
+// Workspace is a tenant owning links and API keys.
+// Each workspace only sees its own links; API keys are bound to exactly one workspace.
+type Workspace struct {
+	// ID is the primary key with auto-increment functionality
+	ID uint `gorm:"primaryKey"`
+
+	// Slug is the unique, URL-safe identifier of the workspace (e.g. "acme")
+	// - it follows the custom alias rules, since namespaced workspaces use it as the first segment of their short URLs
+	Slug string `gorm:"uniqueIndex;size:32;not null"`
+
+	// Name is a human-readable label for the workspace
+	Name string `gorm:"size:100;not null"`
+
+	// NamespacedCodes gives the workspace its own short code namespace
+	// - false: short codes are unique across all non-namespaced workspaces and redirect from /<code>
+	// - true: short codes are unique within the workspace and redirect from /<slug>/<code>
+	// It is fixed at creation: changing it would change the URL of every existing link.
+	NamespacedCodes bool `gorm:"not null;default:false"`
+
+	// CreatedAt automatically stores the timestamp when the record is created
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// LinkNamespace returns the namespace the short codes of this workspace live in.
+func (w *Workspace) LinkNamespace() string {
+	if w.NamespacedCodes {
+		return w.Slug
+	}
+	return ""
+}
@@ -2,109 +2,191 @@ package monitor
 
 import (
 	"context"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
-	"sync"
 	"time"
 
+	"github.com/axellelanca/urlshortener/internal/metrics"
+	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
 )
 
+// maxRedirects is the number of redirects followed before a URL is considered inaccessible,
+// like the default policy of http.Client.
+const maxRedirects = 10
+
+// monitorPageSize is the number of links loaded from the database per monitoring batch.
+// Paging keeps memory usage flat no matter how many links are registered.
+const monitorPageSize = 100
+
 // UrlMonitor manages periodic monitoring of long URLs to check their accessibility.
-// It maintains a state map to track URL status changes and notify when they occur.
+// The last known state of each link is persisted on the link itself (models.Link.HealthStatus),
+// so status changes are detected across restarts and can be used to filter link listings.
 type UrlMonitor struct {
-	linkRepo    repository.LinkRepository // Repository to fetch all links from database
-	interval    time.Duration             // How often to check URLs (e.g., every 30 seconds)
-	knownStates map[uint]bool             // Cache of previous URL states (ID -> accessible/not accessible)
-	mu          sync.Mutex                // Protects concurrent access to knownStates map
-	httpClient  *http.Client              // HTTP client for making requests
+	linkRepo   repository.LinkRepository   // Repository to page through links and store their health status
+	policy     *services.DestinationPolicy // Destinations the monitor may request; nil allows every URL
+	interval   time.Duration               // How often to check URLs (e.g., every 30 seconds)
+	httpClient *http.Client                // HTTP client for making requests
+	logger     *slog.Logger                // Logger tagging every line with component=monitor
 }
 
 // NewUrlMonitor creates and returns a new instance of UrlMonitor.
 // interval parameter determines how frequently URLs will be checked.
-func NewUrlMonitor(linkRepo repository.LinkRepository, interval time.Duration) *UrlMonitor {
-	return &UrlMonitor{
-		linkRepo:    linkRepo,
-		interval:    interval,
-		knownStates: make(map[uint]bool),                     // Initialize empty state map
-		httpClient:  &http.Client{Timeout: 10 * time.Second}, // Initialize HTTP client with timeout
+// The destination policy is enforced on every request the monitor makes, including redirects:
+// links created before the policy existed, or public URLs redirecting to internal addresses,
+// must not make the server probe its private network.
+func NewUrlMonitor(linkRepo repository.LinkRepository, policy *services.DestinationPolicy, interval time.Duration) *UrlMonitor {
+	m := &UrlMonitor{
+		linkRepo: linkRepo,
+		policy:   policy,
+		interval: interval,
+		logger:   slog.Default().With("component", "monitor"),
+	}
+	m.httpClient = &http.Client{
+		Timeout:       10 * time.Second, // Initialize HTTP client with timeout
+		CheckRedirect: m.checkRedirect,
+	}
+	return m
+}
+
+// checkRedirect is the http.Client redirect policy: each redirect target must pass the destination policy.
+func (m *UrlMonitor) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return errors.New("stopped after 10 redirects")
+	}
+	if m.policy == nil {
+		return nil
 	}
+	return m.policy.Check(req.Context(), req.URL.String())
 }
 
 // Start launches the periodic URL monitoring loop.
-// This is a blocking function that runs indefinitely until the program stops.
-func (m *UrlMonitor) Start() {
-	log.Printf("[MONITOR] Starting URL monitor with interval of %v...", m.interval)
+// This is a blocking function that runs until the context is cancelled; a check in progress
+// is abandoned (its remaining links keep their previous state) and the ticker is stopped.
+func (m *UrlMonitor) Start(ctx context.Context) {
+	m.logger.Info("Starting URL monitor", "interval", m.interval)
 	ticker := time.NewTicker(m.interval)
 	defer ticker.Stop()
 
 	// Execute an immediate check on startup before waiting for the first tick
-	m.checkUrls()
-
-	// Main monitoring loop - runs every 'interval' duration
-	for range ticker.C {
-		m.checkUrls()
+	m.checkUrls(ctx)
+
+	// Main monitoring loop - runs every 'interval' duration until shutdown
+	for {
+		select {
+		case <-ctx.Done():
+			m.logger.Info("URL monitor stopped")
+			return
+		case <-ticker.C:
+			m.checkUrls(ctx)
+		}
 	}
 }
 
-// checkUrls performs a status check on all registered long URLs.
-// It compares current state with previous state and logs any changes.
-func (m *UrlMonitor) checkUrls() {
-	log.Println("[MONITOR] Starting URL status verification...")
+// checkUrls performs a status check on all registered long URLs, one page at a time.
+// It compares current state with the persisted state and logs any changes.
+func (m *UrlMonitor) checkUrls(ctx context.Context) {
+	m.logger.Info("Starting URL status verification")
 
-	// Fetch all active long URLs from the repository
-	links, err := m.linkRepo.GetAllLinks()
-	if err != nil {
-		log.Printf("[MONITOR] ERROR retrieving links for monitoring: %v", err)
-		return
+	query := repository.LinkListQuery{
+		SortBy: repository.SortByCreatedAt,
+		Limit:  monitorPageSize,
 	}
+	for {
+		// Fetch the next page of links from the repository
+		links, err := m.linkRepo.ListLinks(query)
+		if err != nil {
+			m.logger.Error("Failed to retrieve links for monitoring", "error", err)
+			return
+		}
 
-	// Iterate through each link and check its current accessibility
-	for _, link := range links {
-		// Test if the URL is currently accessible via HTTP request
-		currentState := m.isUrlAccessible(link.LongURL)
+		for _, link := range links {
+			if ctx.Err() != nil {
+				m.logger.Info("URL status verification interrupted by shutdown")
+				return
+			}
+			m.checkLink(ctx, &link.Link)
+		}
 
-		// Thread-safe access to the state map since multiple goroutines might access it
-		m.mu.Lock()
-		previousState, exists := m.knownStates[link.ID] // Check if we've seen this URL before
-		m.knownStates[link.ID] = currentState           // Update the state cache
-		m.mu.Unlock()
+		// A short page means we reached the end of the table
+		if len(links) < monitorPageSize {
+			break
+		}
+		last := links[len(links)-1]
+		query.After = &repository.LinkCursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+	m.logger.Info("URL status verification completed")
+}
 
-		// If this is the first time checking this link, just log the initial state
-		if !exists {
-			log.Printf("[MONITOR] Initial state for link %s (%s): %s",
-				link.ShortCode, link.LongURL, formatState(currentState))
-			continue
+// checkLink checks a single link, persists its new state and notifies on state changes.
+func (m *UrlMonitor) checkLink(ctx context.Context, link *models.Link) {
+	// Destinations the policy rejects are never requested; their state is left as it was
+	if m.policy != nil {
+		if err := m.policy.Check(ctx, link.LongURL); err != nil {
+			m.logger.Warn("Link destination rejected by the destination policy, not checked",
+				"short_code", link.ShortCode, "long_url", link.LongURL, "error", err)
+			return
 		}
+	}
+
+	// Test if the URL is currently accessible via HTTP request
+	currentState := models.HealthDown
+	if m.isUrlAccessible(ctx, link.LongURL) {
+		currentState = models.HealthUp
+	}
+	// A request cut short by shutdown says nothing about the URL, keep the previous state
+	if ctx.Err() != nil {
+		return
+	}
+	metrics.MonitorCheck(currentState)
+	previousState := link.HealthStatus
+
+	if err := m.linkRepo.UpdateHealthStatus(link.ID, currentState, time.Now()); err != nil {
+		m.logger.Error("Failed to save health status", "short_code", link.ShortCode, "error", err)
+	}
+
+	// If this is the first time checking this link, just log the initial state
+	if previousState == models.HealthUnknown || previousState == "" {
+		m.logger.Info("Initial link state",
+			"short_code", link.ShortCode, "long_url", link.LongURL, "state", formatState(currentState))
+		return
+	}
 
-		// Compare current state with previous state to detect changes
-		// This is where we detect if a URL went from working to broken or vice versa
-		if currentState != previousState {
-			log.Printf("[NOTIFICATION] Link %s (%s) changed from %s to %s!",
-				link.ShortCode, link.LongURL, formatState(previousState), formatState(currentState))
+	// Compare current state with previous state to detect changes
+	// This is where we detect if a URL went from working to broken or vice versa
+	// Going down is logged as a warning so it stands out; recovering is informational
+	if currentState != previousState {
+		level := slog.LevelInfo
+		if currentState == models.HealthDown {
+			level = slog.LevelWarn
 		}
+		m.logger.Log(ctx, level, "Link state changed",
+			"short_code", link.ShortCode, "long_url", link.LongURL,
+			"from", formatState(previousState), "to", formatState(currentState))
 	}
-	log.Println("[MONITOR] URL status verification completed.")
 }
 
 // isUrlAccessible performs an HTTP HEAD request to check if a URL is accessible.
 // Returns true if the URL responds with a successful HTTP status code (2xx or 3xx).
-func (m *UrlMonitor) isUrlAccessible(url string) bool {
+func (m *UrlMonitor) isUrlAccessible(ctx context.Context, url string) bool {
 	// Set a timeout to prevent hanging on slow/unresponsive URLs
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	// Deriving from the monitor context also aborts the request on shutdown
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	// Create HTTP HEAD request (faster than GET since we don't need the response body)
 	req, err := http.NewRequestWithContext(ctx, "HEAD", url, nil)
 	if err != nil {
-		log.Printf("[MONITOR] Error creating request for URL '%s': %v", url, err)
+		m.logger.Warn("Failed to create request", "url", url, "error", err)
 		return false
 	}
 
 	// Execute the HTTP request
 	resp, err := m.httpClient.Do(req)
 	if err != nil {
-		log.Printf("[MONITOR] Error accessing URL '%s': %v", url, err)
+		m.logger.Info("Failed to access URL", "url", url, "error", err)
 		return false
 	}
 	defer resp.Body.Close()
@@ -115,9 +197,9 @@ func (m *UrlMonitor) isUrlAccessible(url string) bool {
 }
 
 // formatState is a utility function to make the state more readable in logs.
-// Converts boolean accessibility state to human-readable string.
-func formatState(accessible bool) string {
-	if accessible {
+// Converts a persisted health status to a human-readable string.
+func formatState(status string) string {
+	if status == models.HealthUp {
 		return "ACCESSIBLE"
 	}
 	return "INACCESSIBLE"
@@ -0,0 +1,94 @@
+package monitor
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
+)
+
+// newTestLink stores a link to longURL in a fresh in-memory repository.
+func newTestLink(t *testing.T, longURL string) (repository.LinkRepository, *models.Link) {
+	t.Helper()
+	linkRepo := repository.NewMemoryLinkRepository(repository.NewMemoryStore())
+	link := &models.Link{ShortCode: "abc123", LongURL: longURL, HealthStatus: models.HealthUnknown}
+	if err := linkRepo.CreateLink(link); err != nil {
+		t.Fatal(err)
+	}
+	return linkRepo, link
+}
+
+// healthStatus returns the health status currently stored for the test link.
+func healthStatus(t *testing.T, linkRepo repository.LinkRepository) string {
+	t.Helper()
+	link, err := linkRepo.GetLinkByShortCode("", "abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return link.HealthStatus
+}
+
+func TestCheckLinkSkipsDestinationsRejectedByThePolicy(t *testing.T) {
+	var hits atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { hits.Add(1) }))
+	defer server.Close()
+
+	policy, err := services.NewDestinationPolicy(services.DestinationPolicyOptions{AllowedSchemes: []string{"http"}, BlockPrivateNetworks: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A link to a loopback address, as stored before the policy existed
+	linkRepo, link := newTestLink(t, server.URL)
+	NewUrlMonitor(linkRepo, policy, time.Minute).checkLink(context.Background(), link)
+	if hits.Load() != 0 {
+		t.Fatal("the monitor requested a destination rejected by the policy")
+	}
+	if status := healthStatus(t, linkRepo); status != models.HealthUnknown {
+		t.Errorf("health status of a skipped link changed to %q", status)
+	}
+
+	// Without a policy the same link is checked
+	NewUrlMonitor(linkRepo, nil, time.Minute).checkLink(context.Background(), link)
+	if hits.Load() != 1 || healthStatus(t, linkRepo) != models.HealthUp {
+		t.Errorf("unchecked link without a policy: %d requests, status %q", hits.Load(), healthStatus(t, linkRepo))
+	}
+}
+
+func TestCheckLinkDoesNotFollowRedirectsRejectedByThePolicy(t *testing.T) {
+	var targetHits atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/target", func(w http.ResponseWriter, r *http.Request) { targetHits.Add(1) })
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, strings.Replace(server.URL, "127.0.0.1", "localhost", 1)+"/target", http.StatusFound)
+	})
+
+	blocklist := filepath.Join(t.TempDir(), "blocklist.txt")
+	if err := os.WriteFile(blocklist, []byte("localhost\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := services.NewDestinationPolicy(services.DestinationPolicyOptions{AllowedSchemes: []string{"http"}, BlocklistPath: blocklist})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The link itself passes the policy, the destination it redirects to does not
+	linkRepo, link := newTestLink(t, server.URL+"/start")
+	NewUrlMonitor(linkRepo, policy, time.Minute).checkLink(context.Background(), link)
+	if targetHits.Load() != 0 {
+		t.Fatal("the monitor followed a redirect to a destination rejected by the policy")
+	}
+	if status := healthStatus(t, linkRepo); status != models.HealthDown {
+		t.Errorf("health status %q, want %q", status, models.HealthDown)
+	}
+}
@@ -0,0 +1,162 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often the in-process limiter forgets the buckets of idle clients.
+const sweepInterval = time.Minute
+
+// Decision is the outcome of a rate limit check.
+type Decision struct {
+	Allowed    bool          // Whether the request may proceed
+	Limit      int           // Burst size of the bucket, i.e. the number of requests allowed at once
+	Remaining  int           // Whole tokens left in the bucket after this request
+	RetryAfter time.Duration // When denied, how long until the next token is available
+}
+
+// Limiter decides whether a client may perform one more request.
+// The in-process MemoryLimiter is the only implementation today; a limiter backed by a store
+// shared by several server instances (e.g. Redis) can be plugged in by implementing this interface.
+type Limiter interface {
+	// Allow consumes one token from the bucket identified by key (e.g. "key:12" or "ip:203.0.113.7").
+	// An error means the limiter could not decide; callers let the request through rather than fail it.
+	Allow(ctx context.Context, key string) (Decision, error)
+
+	// AllowN consumes n tokens at once, for requests that count as several operations (e.g. a batch
+	// of links). Either all n tokens are taken or none; a request costing more than the burst size
+	// is never allowed.
+	AllowN(ctx context.Context, key string, n int) (Decision, error)
+
+	// Burst returns the bucket capacity, i.e. the largest n AllowN can ever allow.
+	Burst() int
+}
+
+// Rule configures a token bucket: the bucket holds at most Burst tokens and is refilled
+// at RequestsPerMinute tokens per minute, so clients can briefly exceed the sustained rate.
+type Rule struct {
+	RequestsPerMinute int // Sustained rate; 0 or less disables the rule
+	Burst             int // Bucket capacity; defaults to RequestsPerMinute when 0 or less
+}
+
+// Enabled reports whether the rule limits anything.
+func (r Rule) Enabled() bool {
+	return r.RequestsPerMinute > 0
+}
+
+// bucket is the state of one client's token bucket.
+type bucket struct {
+	tokens float64   // Tokens available at time last
+	last   time.Time // Last time tokens were computed
+}
+
+// MemoryLimiter is a thread-safe, in-process token bucket limiter.
+// Buckets live in the memory of the server, so each instance of a horizontally scaled
+// deployment enforces its own limits. Buckets of idle clients are dropped once they have
+// refilled completely, which keeps memory bounded by the number of recently active clients.
+type MemoryLimiter struct {
+	rate  float64 // Tokens added per second
+	burst float64 // Bucket capacity
+
+	mu        sync.Mutex         // Guards every field below
+	buckets   map[string]*bucket // Bucket per client key
+	lastSweep time.Time          // Last time idle buckets were dropped
+}
+
+// NewMemoryLimiter creates an in-process token bucket limiter.
+// Parameters:
+//   - rule: sustained rate and burst size; rule.Enabled() must be true
+//
+// Returns:
+//   - *MemoryLimiter: limiter ready for concurrent use
+func NewMemoryLimiter(rule Rule) *MemoryLimiter {
+	burst := rule.Burst
+	if burst <= 0 {
+		burst = rule.RequestsPerMinute
+	}
+	return &MemoryLimiter{
+		rate:      float64(rule.RequestsPerMinute) / 60,
+		burst:     float64(burst),
+		buckets:   make(map[string]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow consumes one token from the client's bucket, creating a full bucket for a new client.
+// Parameters:
+//   - ctx: unused by the in-process limiter, present for shared-store implementations
+//   - key: identifies the client
+//
+// Returns:
+//   - Decision: whether the request is allowed and, if not, when to retry
+//   - error: always nil
+func (l *MemoryLimiter) Allow(ctx context.Context, key string) (Decision, error) {
+	return l.AllowN(ctx, key, 1)
+}
+
+// AllowN consumes n tokens from the client's bucket if it holds that many, creating a full bucket for a new client.
+// Parameters:
+//   - ctx: unused by the in-process limiter, present for shared-store implementations
+//   - key: identifies the client
+//   - n: number of tokens the request costs; values below 1 cost nothing
+//
+// Returns:
+//   - Decision: whether the request is allowed and, if not, when to retry; RetryAfter is 0 when n exceeds the burst
+//   - error: always nil
+func (l *MemoryLimiter) AllowN(ctx context.Context, key string, n int) (Decision, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		l.sweep(now)
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	} else {
+		b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+		b.last = now
+	}
+
+	cost := math.Max(0, float64(n))
+	decision := Decision{Limit: int(l.burst)}
+	if b.tokens >= cost {
+		b.tokens -= cost
+		decision.Allowed = true
+		decision.Remaining = int(b.tokens)
+		return decision, nil
+	}
+
+	decision.Remaining = int(b.tokens)
+	if cost > l.burst {
+		// Waiting would not help: the bucket never holds that many tokens
+		return decision, nil
+	}
+	// Time until the bucket holds enough tokens again
+	decision.RetryAfter = time.Duration((cost - b.tokens) / l.rate * float64(time.Second))
+	return decision, nil
+}
+
+// Burst returns the number of tokens a full bucket holds.
+func (l *MemoryLimiter) Burst() int {
+	return int(l.burst)
+}
+
+// sweep drops the buckets that have been idle long enough to be full again:
+// forgetting them is equivalent to keeping them, since a new client starts with a full bucket.
+// Callers must hold the lock.
+func (l *MemoryLimiter) sweep(now time.Time) {
+	refillTime := time.Duration(l.burst / l.rate * float64(time.Second))
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= refillTime {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastSweep = now
+}
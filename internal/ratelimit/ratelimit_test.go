@@ -0,0 +1,85 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiterBurstAndRefill(t *testing.T) {
+	limiter := NewMemoryLimiter(Rule{RequestsPerMinute: 60, Burst: 3})
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		decision, _ := limiter.Allow(ctx, "ip:a")
+		if !decision.Allowed || decision.Remaining != 2-i || decision.Limit != 3 {
+			t.Fatalf("request %d: unexpected decision %+v", i+1, decision)
+		}
+	}
+	decision, _ := limiter.Allow(ctx, "ip:a")
+	if decision.Allowed || decision.RetryAfter <= 0 || decision.RetryAfter > time.Second {
+		t.Fatalf("expected a denial with a retry within 1s, got %+v", decision)
+	}
+
+	// Buckets are per client
+	if decision, _ := limiter.Allow(ctx, "ip:b"); !decision.Allowed {
+		t.Fatal("another client was limited")
+	}
+
+	// One token per second: simulate the passage of time by moving the bucket back
+	limiter.buckets["ip:a"].last = limiter.buckets["ip:a"].last.Add(-1500 * time.Millisecond)
+	if decision, _ := limiter.Allow(ctx, "ip:a"); !decision.Allowed {
+		t.Fatal("bucket was not refilled")
+	}
+}
+
+func TestMemoryLimiterBurstDefaultsToRate(t *testing.T) {
+	limiter := NewMemoryLimiter(Rule{RequestsPerMinute: 5})
+	if decision, _ := limiter.Allow(context.Background(), "ip:a"); decision.Limit != 5 || limiter.Burst() != 5 {
+		t.Fatalf("expected the burst to default to the rate, got %d (Burst %d)", decision.Limit, limiter.Burst())
+	}
+}
+
+func TestMemoryLimiterAllowN(t *testing.T) {
+	limiter := NewMemoryLimiter(Rule{RequestsPerMinute: 60, Burst: 10})
+	ctx := context.Background()
+
+	if decision, _ := limiter.AllowN(ctx, "key:1", 7); !decision.Allowed || decision.Remaining != 3 {
+		t.Fatalf("unexpected decision %+v", decision)
+	}
+
+	// Not enough tokens: nothing is consumed and the wait covers the missing tokens
+	decision, _ := limiter.AllowN(ctx, "key:1", 5)
+	if decision.Allowed || decision.Remaining != 3 || decision.RetryAfter < 1900*time.Millisecond {
+		t.Fatalf("expected a denial waiting for 2 tokens, got %+v", decision)
+	}
+	if decision, _ := limiter.AllowN(ctx, "key:1", 3); !decision.Allowed {
+		t.Fatal("tokens were consumed by a denied request")
+	}
+
+	// More than the burst can never be allowed, so there is no point retrying
+	fresh, _ := limiter.AllowN(ctx, "key:2", 11)
+	if fresh.Allowed || fresh.RetryAfter != 0 || fresh.Remaining != 10 {
+		t.Fatalf("expected a definitive denial, got %+v", fresh)
+	}
+}
+
+func TestMemoryLimiterSweepsIdleBuckets(t *testing.T) {
+	limiter := NewMemoryLimiter(Rule{RequestsPerMinute: 60, Burst: 2})
+	ctx := context.Background()
+	limiter.Allow(ctx, "ip:idle")
+	limiter.Allow(ctx, "ip:busy")
+	limiter.Allow(ctx, "ip:busy")
+
+	// Both buckets refill within 2s; make only the idle one old enough to be forgotten
+	limiter.buckets["ip:idle"].last = time.Now().Add(-time.Minute)
+	limiter.lastSweep = time.Now().Add(-2 * sweepInterval)
+	limiter.Allow(ctx, "ip:other")
+
+	if _, ok := limiter.buckets["ip:idle"]; ok {
+		t.Error("idle bucket was not dropped")
+	}
+	if _, ok := limiter.buckets["ip:busy"]; !ok {
+		t.Error("active bucket was dropped")
+	}
+}
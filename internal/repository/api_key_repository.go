@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// APIKeyRepository is an interface that defines data access methods for API keys.
+type APIKeyRepository interface {
+	// CreateAPIKey inserts a new API key (its hash, never the plain key).
+	CreateAPIKey(key *models.APIKey) error
+
+	// GetAPIKeyByHash retrieves an API key, revoked or not, from the hash of its plain value.
+	// This is the lookup performed on every authenticated request.
+	GetAPIKeyByHash(keyHash string) (*models.APIKey, error)
+
+	// GetAPIKeyByID retrieves an API key by its database ID.
+	GetAPIKeyByID(id uint) (*models.APIKey, error)
+
+	// ListAPIKeys returns every API key, revoked ones included, oldest first.
+	ListAPIKeys() ([]models.APIKey, error)
+
+	// RevokeAPIKey marks a key as revoked at the given time.
+	RevokeAPIKey(id uint, revokedAt time.Time) error
+
+	// TouchAPIKey records that a key has just been used.
+	TouchAPIKey(id uint, usedAt time.Time) error
+}
+
+// GormAPIKeyRepository is the GORM-based implementation of the APIKeyRepository interface.
+type GormAPIKeyRepository struct {
+	db *gorm.DB // GORM database connection instance
+}
+
+// NewAPIKeyRepository creates and returns a new instance of GormAPIKeyRepository.
+// Parameters:
+//   - db: GORM database connection to use for all operations
+//
+// Returns:
+//   - *GormAPIKeyRepository: configured repository instance ready for use
+func NewAPIKeyRepository(db *gorm.DB) *GormAPIKeyRepository {
+	return &GormAPIKeyRepository{db: db}
+}
+
+// CreateAPIKey inserts a new API key record into the database.
+// Parameters:
+//   - key: the API key to insert; its ID is filled in on success
+//
+// Returns:
+//   - error: nil on success, or database error if insertion fails
+func (r *GormAPIKeyRepository) CreateAPIKey(key *models.APIKey) error {
+	if err := r.db.Create(key).Error; err != nil {
+		return fmt.Errorf("failed to create API key: %w", err)
+	}
+	return nil
+}
+
+// GetAPIKeyByHash retrieves an API key from the hash of its plain value.
+// Parameters:
+//   - keyHash: hex-encoded SHA-256 hash of the plain key
+//
+// Returns:
+//   - *models.APIKey: the found key
+//   - error: gorm.ErrRecordNotFound if no key has this hash, or database error
+func (r *GormAPIKeyRepository) GetAPIKeyByHash(keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// GetAPIKeyByID retrieves an API key by its database ID.
+// Parameters:
+//   - id: the database ID of the key
+//
+// Returns:
+//   - *models.APIKey: the found key
+//   - error: gorm.ErrRecordNotFound if no key has this ID, or database error
+func (r *GormAPIKeyRepository) GetAPIKeyByID(id uint) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.First(&key, id).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// ListAPIKeys returns every API key ordered by ID.
+// Returns:
+//   - []models.APIKey: all keys, revoked ones included
+//   - error: nil on success, or database error if query fails
+func (r *GormAPIKeyRepository) ListAPIKeys() ([]models.APIKey, error) {
+	var keys []models.APIKey
+	if err := r.db.Order("id").Find(&keys).Error; err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey sets the revocation date of a key.
+// Parameters:
+//   - id: the database ID of the key
+//   - revokedAt: the revocation time
+//
+// Returns:
+//   - error: nil on success, or database error if the update fails
+func (r *GormAPIKeyRepository) RevokeAPIKey(id uint, revokedAt time.Time) error {
+	if err := r.db.Model(&models.APIKey{}).Where("id = ?", id).Update("revoked_at", revokedAt).Error; err != nil {
+		return fmt.Errorf("failed to revoke API key %d: %w", id, err)
+	}
+	return nil
+}
+
+// TouchAPIKey sets the last usage date of a key.
+// Parameters:
+//   - id: the database ID of the key
+//   - usedAt: the time the key was used
+//
+// Returns:
+//   - error: nil on success, or database error if the update fails
+func (r *GormAPIKeyRepository) TouchAPIKey(id uint, usedAt time.Time) error {
+	if err := r.db.Model(&models.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error; err != nil {
+		return fmt.Errorf("failed to record usage of API key %d: %w", id, err)
+	}
+	return nil
+}
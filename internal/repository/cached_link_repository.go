@@ -0,0 +1,265 @@
+package repository
+
+import (
+	"container/list"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// LinkCacheOptions configures the read-through cache of CachedLinkRepository.
+type LinkCacheOptions struct {
+	Size        int           // Maximum number of cached short codes; the least recently used entry is evicted beyond it
+	TTL         time.Duration // How long a found link is served from the cache
+	NegativeTTL time.Duration // How long an unknown short code is remembered as missing; 0 disables negative caching
+}
+
+// LinkCacheStats is a snapshot of the cache counters, exposed for monitoring.
+type LinkCacheStats struct {
+	Hits      uint64 `json:"hits"`      // Lookups answered from the cache (including cached "not found")
+	Misses    uint64 `json:"misses"`    // Lookups that had to query the underlying repository
+	Evictions uint64 `json:"evictions"` // Entries dropped to respect the size bound
+	Size      int    `json:"size"`      // Number of entries currently cached
+	Capacity  int    `json:"capacity"`  // Maximum number of entries
+}
+
+// linkCacheEntry is one cached lookup result; a nil link records that the short code does not exist.
+type linkCacheEntry struct {
+	shortCode string // Cache key, see linkCacheKey
+	link      *models.Link
+	expiresAt time.Time
+}
+
+// CachedLinkRepository is a LinkRepository decorator that serves GetLinkByShortCode from a
+// size-bounded LRU cache with TTL, so redirects do not hit the database for every request.
+// Unknown short codes are cached too (negative caching) to absorb scans of random codes.
+// Writes going through this repository, URL monitor results included, invalidate the affected entries;
+// changes made by another process (e.g. the CLI while the server runs) become visible to redirects once
+// the entry's TTL has expired. Management operations must not read through the cache (see Uncached).
+type CachedLinkRepository struct {
+	LinkRepository // Underlying repository; every method not overridden below is passed through
+
+	options LinkCacheOptions         // Size and TTLs
+	mu      sync.Mutex               // Guards every field below
+	entries map[string]*list.Element // linkCacheKey -> element of order holding a *linkCacheEntry
+	ids     map[uint]string          // Link ID -> linkCacheKey of the cached links, for invalidations by ID
+	order   *list.List               // Most recently used entry at the front
+
+	// generation is bumped by every invalidation so a lookup started before a write
+	// cannot put the pre-write value back into the cache
+	generation uint64
+
+	hits      uint64 // Counters reported by Stats
+	misses    uint64
+	evictions uint64
+}
+
+// NewCachedLinkRepository wraps a link repository with a read-through short-code cache.
+// Parameters:
+//   - next: the repository actually storing the links (GORM or in-memory)
+//   - options: cache size and TTLs
+//
+// Returns:
+//   - *CachedLinkRepository: repository usable anywhere a LinkRepository is expected
+func NewCachedLinkRepository(next LinkRepository, options LinkCacheOptions) *CachedLinkRepository {
+	return &CachedLinkRepository{
+		LinkRepository: next,
+		options:        options,
+		entries:        make(map[string]*list.Element),
+		ids:            make(map[uint]string),
+		order:          list.New(),
+	}
+}
+
+// GetLinkByShortCode returns the cached link for a short code, or loads it from the underlying
+// repository and caches the result. A cached "not found" is returned as gorm.ErrRecordNotFound.
+// Parameters:
+//   - namespace: the namespace of the short code ("" for the global namespace)
+//   - shortCode: the short code to resolve
+//
+// Returns:
+//   - *models.Link: a copy of the link, safe to modify without affecting the cache
+//   - error: gorm.ErrRecordNotFound if the short code does not exist, or errors from the underlying repository
+func (r *CachedLinkRepository) GetLinkByShortCode(namespace, shortCode string) (*models.Link, error) {
+	key := linkCacheKey(namespace, shortCode)
+	link, found, ok, generation := r.lookup(key)
+	if ok {
+		if !found {
+			return nil, gorm.ErrRecordNotFound
+		}
+		return link, nil
+	}
+
+	link, err := r.LinkRepository.GetLinkByShortCode(namespace, shortCode)
+	if err != nil {
+		// Only a definite "not found" is cached; transient database errors must not be remembered
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			r.store(key, nil, r.options.NegativeTTL, generation)
+		}
+		return nil, err
+	}
+	r.store(key, link, r.options.TTL, generation)
+	return link, nil
+}
+
+// CreateLink creates the link and forgets any cached "not found" for its short code.
+func (r *CachedLinkRepository) CreateLink(link *models.Link) error {
+	err := r.LinkRepository.CreateLink(link)
+	r.Invalidate(link.Namespace, link.ShortCode)
+	return err
+}
+
+// UpdateLink saves the given columns of the link and invalidates its cache entry.
+func (r *CachedLinkRepository) UpdateLink(link *models.Link, columns ...string) error {
+	err := r.LinkRepository.UpdateLink(link, columns...)
+	r.Invalidate(link.Namespace, link.ShortCode)
+	return err
+}
+
+// DeleteLink soft-deletes the link and invalidates its cache entry.
+func (r *CachedLinkRepository) DeleteLink(link *models.Link) error {
+	err := r.LinkRepository.DeleteLink(link)
+	r.Invalidate(link.Namespace, link.ShortCode)
+	return err
+}
+
+// UpdateHealthStatus records a monitor check and drops the link's cache entry, so that a link
+// loaded from the cache afterwards never carries an older health status.
+// The entry is found by ID since the monitor does not pass the short code.
+func (r *CachedLinkRepository) UpdateHealthStatus(linkID uint, status string, checkedAt time.Time) error {
+	err := r.LinkRepository.UpdateHealthStatus(linkID, status, checkedAt)
+	r.invalidateID(linkID)
+	return err
+}
+
+// Uncached returns the repository behind the cache, for reads that must see the latest stored state
+// (e.g. loading a link before modifying it).
+func (r *CachedLinkRepository) Uncached() LinkRepository {
+	return r.LinkRepository
+}
+
+// Invalidate removes a short code from the cache so the next lookup reads the underlying repository.
+// Parameters:
+//   - namespace: the namespace of the short code ("" for the global namespace)
+//   - shortCode: the short code to forget
+func (r *CachedLinkRepository) Invalidate(namespace, shortCode string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generation++
+	if element, ok := r.entries[linkCacheKey(namespace, shortCode)]; ok {
+		r.remove(element)
+	}
+}
+
+// invalidateID removes the entry of the link with the given ID, if cached, and bumps the generation
+// so that a lookup in flight cannot cache the pre-write value.
+func (r *CachedLinkRepository) invalidateID(linkID uint) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generation++
+	if key, ok := r.ids[linkID]; ok {
+		r.remove(r.entries[key])
+	}
+}
+
+// linkCacheKey builds the cache key of a short code: "<code>" or "<namespace>/<code>".
+// Short codes never contain a slash, so keys of different namespaces cannot collide.
+func linkCacheKey(namespace, shortCode string) string {
+	if namespace == "" {
+		return shortCode
+	}
+	return namespace + "/" + shortCode
+}
+
+// Stats returns a snapshot of the cache counters.
+func (r *CachedLinkRepository) Stats() LinkCacheStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return LinkCacheStats{
+		Hits:      r.hits,
+		Misses:    r.misses,
+		Evictions: r.evictions,
+		Size:      r.order.Len(),
+		Capacity:  r.options.Size,
+	}
+}
+
+// lookup returns the cached result for a short code and counts the hit or miss.
+// ok is false when the short code is not cached or its entry has expired; found is false for a cached "not found".
+// On a miss, the returned generation must be passed to store along with the loaded result.
+func (r *CachedLinkRepository) lookup(shortCode string) (link *models.Link, found bool, ok bool, generation uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	element, cached := r.entries[shortCode]
+	if !cached {
+		r.misses++
+		return nil, false, false, r.generation
+	}
+	entry := element.Value.(*linkCacheEntry)
+	if time.Now().After(entry.expiresAt) {
+		r.remove(element)
+		r.misses++
+		return nil, false, false, r.generation
+	}
+
+	r.order.MoveToFront(element)
+	r.hits++
+	if entry.link == nil {
+		return nil, false, true, r.generation
+	}
+	linkCopy := *entry.link
+	return &linkCopy, true, true, r.generation
+}
+
+// store caches a lookup result (nil link for "not found") and evicts the least recently used
+// entries beyond the configured size. The result is dropped if an invalidation happened since
+// the lookup that produced it (generation mismatch), as it may predate a write.
+func (r *CachedLinkRepository) store(shortCode string, link *models.Link, ttl time.Duration, generation uint64) {
+	if r.options.Size <= 0 || ttl <= 0 {
+		return
+	}
+
+	entry := &linkCacheEntry{shortCode: shortCode, expiresAt: time.Now().Add(ttl)}
+	if link != nil {
+		// Keep a private copy so callers modifying their link cannot corrupt the cache
+		linkCopy := *link
+		entry.link = &linkCopy
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if generation != r.generation {
+		return
+	}
+	if element, ok := r.entries[shortCode]; ok {
+		r.remove(element)
+	}
+	r.entries[shortCode] = r.order.PushFront(entry)
+	if link != nil {
+		r.ids[link.ID] = shortCode
+	}
+
+	for r.order.Len() > r.options.Size {
+		r.remove(r.order.Back())
+		r.evictions++
+	}
+}
+
+// remove drops a cache element from the LRU list and both indexes.
+// Callers must hold the lock.
+func (r *CachedLinkRepository) remove(element *list.Element) {
+	entry := element.Value.(*linkCacheEntry)
+	r.order.Remove(element)
+	delete(r.entries, entry.shortCode)
+	if entry.link != nil && r.ids[entry.link.ID] == entry.shortCode {
+		delete(r.ids, entry.link.ID)
+	}
+}
@@ -0,0 +1,156 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// countingLinkRepository counts the short-code lookups reaching the underlying repository.
+type countingLinkRepository struct {
+	LinkRepository
+	lookups int
+}
+
+func (r *countingLinkRepository) GetLinkByShortCode(namespace, shortCode string) (*models.Link, error) {
+	r.lookups++
+	return r.LinkRepository.GetLinkByShortCode(namespace, shortCode)
+}
+
+// newTestCache wraps an in-memory link repository with a cache and returns both layers.
+func newTestCache(options LinkCacheOptions) (*CachedLinkRepository, *countingLinkRepository) {
+	underlying := &countingLinkRepository{LinkRepository: NewMemoryLinkRepository(NewMemoryStore())}
+	return NewCachedLinkRepository(underlying, options), underlying
+}
+
+func TestCachedLinkRepositoryServesHitsAndMisses(t *testing.T) {
+	cache, underlying := newTestCache(LinkCacheOptions{Size: 10, TTL: time.Minute, NegativeTTL: time.Minute})
+	createTestLink(t, cache, "abc", "example.com", time.Now())
+
+	for i := 0; i < 3; i++ {
+		if _, err := cache.GetLinkByShortCode("", "abc"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := cache.GetLinkByShortCode("", "nope"); !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("expected gorm.ErrRecordNotFound, got %v", err)
+		}
+	}
+	if underlying.lookups != 2 {
+		t.Errorf("expected 2 lookups to reach the repository, got %d", underlying.lookups)
+	}
+	if stats := cache.Stats(); stats.Hits != 4 || stats.Misses != 2 || stats.Size != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	// Creating the missing code must forget its cached "not found"
+	createTestLink(t, cache, "nope", "example.com", time.Now())
+	if _, err := cache.GetLinkByShortCode("", "nope"); err != nil {
+		t.Errorf("created link still cached as missing: %v", err)
+	}
+}
+
+func TestCachedLinkRepositoryEvictsLeastRecentlyUsed(t *testing.T) {
+	cache, underlying := newTestCache(LinkCacheOptions{Size: 2, TTL: time.Minute})
+	for _, code := range []string{"aaa", "bbb", "ccc"} {
+		createTestLink(t, cache, code, "example.com", time.Now())
+	}
+
+	cache.GetLinkByShortCode("", "aaa")
+	cache.GetLinkByShortCode("", "bbb")
+	cache.GetLinkByShortCode("", "aaa") // "bbb" becomes the least recently used entry
+	cache.GetLinkByShortCode("", "ccc") // evicts "bbb"
+
+	underlying.lookups = 0
+	cache.GetLinkByShortCode("", "aaa")
+	cache.GetLinkByShortCode("", "bbb")
+	if underlying.lookups != 1 {
+		t.Errorf("expected only the evicted code to reach the repository, got %d lookups", underlying.lookups)
+	}
+	if stats := cache.Stats(); stats.Evictions != 2 || stats.Size != 2 {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+}
+
+func TestCachedLinkRepositoryDropsResultsOlderThanAnInvalidation(t *testing.T) {
+	cache, _ := newTestCache(LinkCacheOptions{Size: 10, TTL: time.Minute})
+	link := createTestLink(t, cache, "abc", "example.com", time.Now())
+
+	// A lookup misses and reads the link, then a write invalidates the code before the result is stored
+	key := linkCacheKey("", "abc")
+	_, _, _, generation := cache.lookup(key)
+	stale := *link
+	cache.Invalidate("", "abc")
+	cache.store(key, &stale, time.Minute, generation)
+
+	if stats := cache.Stats(); stats.Size != 0 {
+		t.Fatalf("a result read before the invalidation was cached (%d entries)", stats.Size)
+	}
+}
+
+func TestCachedLinkRepositoryInvalidatesOnHealthUpdate(t *testing.T) {
+	cache, underlying := newTestCache(LinkCacheOptions{Size: 10, TTL: time.Minute})
+	link := createTestLink(t, cache, "abc", "example.com", time.Now())
+	cache.GetLinkByShortCode("", "abc")
+
+	if err := cache.UpdateHealthStatus(link.ID, models.HealthDown, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	underlying.lookups = 0
+	cached, err := cache.GetLinkByShortCode("", "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if underlying.lookups != 1 || cached.HealthStatus != models.HealthDown {
+		t.Errorf("health update not visible through the cache: lookups=%d status=%s", underlying.lookups, cached.HealthStatus)
+	}
+	if stats := cache.Stats(); stats.Size != 1 {
+		t.Errorf("expected the reloaded link to be cached again, got %d entries", stats.Size)
+	}
+}
+
+func TestUpdateLinkWritesOnlyGivenColumns(t *testing.T) {
+	for name, newBackend := range linkBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			linkRepo, _ := newBackend()
+			link := createTestLink(t, linkRepo, "abc", "example.com", time.Now())
+
+			// A copy loaded before the monitor check and a destination change made elsewhere
+			stale := *link
+			if err := linkRepo.UpdateHealthStatus(link.ID, models.HealthDown, time.Now()); err != nil {
+				t.Fatal(err)
+			}
+			changed := *link
+			changed.LongURL = "https://example.org/new"
+			if err := linkRepo.UpdateLink(&changed, "long_url"); err != nil {
+				t.Fatal(err)
+			}
+
+			stale.Disabled = true
+			if err := linkRepo.UpdateLink(&stale, "disabled"); err != nil {
+				t.Fatal(err)
+			}
+
+			stored, err := linkRepo.GetLinkByShortCode("", "abc")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !stored.Disabled || stored.LongURL != changed.LongURL || stored.HealthStatus != models.HealthDown {
+				t.Errorf("lost update: disabled=%v long_url=%s health_status=%s", stored.Disabled, stored.LongURL, stored.HealthStatus)
+			}
+
+			stale.Disabled = false
+			if err := linkRepo.UpdateLink(&stale, "disabled"); err != nil {
+				t.Fatal(err)
+			}
+			if stored, _ = linkRepo.GetLinkByShortCode("", "abc"); stored.Disabled {
+				t.Error("a false value was not written")
+			}
+			if err := linkRepo.UpdateLink(&stale); err == nil {
+				t.Error("expected an error when no column is given")
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package repository
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/axellelanca/urlshortener/internal/models"
 	"gorm.io/gorm"
@@ -15,11 +16,61 @@ type ClickRepository interface {
 	// This method is used by background workers to persistently store click events.
 	CreateClick(click *models.Click) error
 
+	// CreateClicks inserts several click records at once with multi-row inserts.
+	// Used by background workers to persist a batch of click events in a single round trip.
+	CreateClicks(clicks []models.Click) error
+
 	// CountClicksByLinkID returns the total number of clicks for a specific link ID.
 	// This is used for analytics and statistics generation.
 	CountClicksByLinkID(linkID uint) (int, error)
+
+	// CountUniqueVisitorsByLinkID returns the number of distinct visitor hashes recorded for a link.
+	// Clicks recorded before visitor hashing existed are ignored.
+	CountUniqueVisitorsByLinkID(linkID uint) (int, error)
+
+	// CountClicksByInterval groups the clicks of a link into time buckets and counts each bucket.
+	// Only non-empty buckets are returned; filling the gaps is left to the caller.
+	CountClicksByInterval(linkID uint, granularity string, from, to time.Time) ([]ClickBucket, error)
+
+	// CountClicksByUserAgent counts the clicks of a link per distinct raw User-Agent header.
+	// Used to build browser, OS and device breakdowns.
+	CountClicksByUserAgent(linkID uint) ([]GroupCount, error)
+
+	// CountClicksByCountry counts the clicks of a link per resolved country code.
+	// Clicks without a resolved country are grouped under an empty value.
+	CountClicksByCountry(linkID uint) ([]GroupCount, error)
+
+	// CountClicksByReferrer counts the clicks of a link per referrer host, keeping the most frequent ones.
+	// Direct clicks (no referrer) are grouped under an empty value.
+	CountClicksByReferrer(linkID uint, limit int) ([]GroupCount, error)
+}
+
+// GroupCount is the number of clicks sharing the same value of a column (user agent, referrer, country...).
+type GroupCount struct {
+	Value string `json:"value"` // The grouped column value
+	Count int    `json:"count"` // Number of clicks with this value
+}
+
+// Time bucket sizes accepted by CountClicksByInterval.
+const (
+	GranularityHour = "hour"
+	GranularityDay  = "day"
+	GranularityWeek = "week" // Weeks start on Monday
+)
+
+// ClickBucket is the number of clicks recorded in one time bucket.
+type ClickBucket struct {
+	Start time.Time // Beginning of the bucket, in UTC
+	Count int       // Number of clicks in [Start, Start+granularity)
 }
 
+// clickInsertBatchSize is the maximum number of rows per INSERT statement in CreateClicks.
+const clickInsertBatchSize = 500
+
+// bucketTimeLayout is the textual format in which bucket start times are selected from the database.
+// Formatting in SQL keeps the scan identical across drivers that return timestamps differently.
+const bucketTimeLayout = "2006-01-02 15:04:05"
+
 // GormClickRepository is the GORM-based implementation of the ClickRepository interface.
 // It uses GORM ORM to interact with the underlying database (SQLite in this case).
 type GormClickRepository struct {
@@ -53,6 +104,24 @@ func (r *GormClickRepository) CreateClick(click *models.Click) error {
 	return nil
 }
 
+// CreateClicks inserts a batch of click records using multi-row INSERT statements.
+// Large batches are split into chunks of clickInsertBatchSize rows to stay below the
+// bound-parameter limits of the database drivers. All chunks run in one transaction.
+// Parameters:
+//   - clicks: the Click models to insert; their IDs are filled in on success
+//
+// Returns:
+//   - error: nil on success, or database error if insertion fails (no click of the batch is saved)
+func (r *GormClickRepository) CreateClicks(clicks []models.Click) error {
+	if len(clicks) == 0 {
+		return nil
+	}
+	if err := r.db.CreateInBatches(clicks, clickInsertBatchSize).Error; err != nil {
+		return fmt.Errorf("failed to create %d clicks: %w", len(clicks), err)
+	}
+	return nil
+}
+
 // CountClicksByLinkID counts the total number of clicks for a given link ID.
 // This method is used for generating statistics and analytics reports.
 // It performs a SQL COUNT query filtered by the link_id column.
@@ -72,3 +141,156 @@ func (r *GormClickRepository) CountClicksByLinkID(linkID uint) (int, error) {
 	// Convert int64 to int for consistency with interface return type
 	return int(count), nil
 }
+
+// CountUniqueVisitorsByLinkID counts the distinct visitor hashes of a link.
+// Parameters:
+//   - linkID: the database ID of the link to count visitors for
+//
+// Returns:
+//   - int: number of distinct non-empty visitor hashes
+//   - error: nil on success, or database error if query fails
+func (r *GormClickRepository) CountUniqueVisitorsByLinkID(linkID uint) (int, error) {
+	var count int64
+	err := r.db.Model(&models.Click{}).
+		Where("link_id = ? AND visitor_hash <> ''", linkID).
+		Distinct("visitor_hash").
+		Count(&count).Error
+	if err != nil {
+		return 0, fmt.Errorf("failed to count unique visitors for link ID %d: %w", linkID, err)
+	}
+	return int(count), nil
+}
+
+// CountClicksByInterval counts the clicks of a link per hour, day or week within [from, to).
+// Bucketing is done in SQL so only one row per non-empty bucket leaves the database.
+// Parameters:
+//   - linkID: the database ID of the link
+//   - granularity: GranularityHour, GranularityDay or GranularityWeek
+//   - from: inclusive lower bound of the click timestamps
+//   - to: exclusive upper bound of the click timestamps
+//
+// Returns:
+//   - []ClickBucket: non-empty buckets in chronological order
+//   - error: nil on success, or database error if query fails
+func (r *GormClickRepository) CountClicksByInterval(linkID uint, granularity string, from, to time.Time) ([]ClickBucket, error) {
+	bucketExpr, err := bucketExpression(r.db.Dialector.Name(), granularity)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		Bucket string
+		Count  int
+	}
+	err = r.db.Model(&models.Click{}).
+		Select(bucketExpr+" AS bucket, COUNT(*) AS count").
+		Where("link_id = ? AND timestamp >= ? AND timestamp < ?", linkID, from.UTC(), to.UTC()).
+		Group("bucket").
+		Order("bucket").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate clicks for link ID %d: %w", linkID, err)
+	}
+
+	buckets := make([]ClickBucket, 0, len(rows))
+	for _, row := range rows {
+		start, err := time.Parse(bucketTimeLayout, row.Bucket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse click bucket '%s': %w", row.Bucket, err)
+		}
+		buckets = append(buckets, ClickBucket{Start: start, Count: row.Count})
+	}
+	return buckets, nil
+}
+
+// bucketExpression returns the SQL expression that truncates a click timestamp to the start
+// of its bucket, formatted with bucketTimeLayout, for the given database dialect.
+func bucketExpression(dialect, granularity string) (string, error) {
+	if dialect == "postgres" {
+		switch granularity {
+		case GranularityHour, GranularityDay, GranularityWeek:
+			return fmt.Sprintf("to_char(date_trunc('%s', timestamp AT TIME ZONE 'UTC'), 'YYYY-MM-DD HH24:MI:SS')", granularity), nil
+		}
+		return "", fmt.Errorf("unsupported granularity '%s'", granularity)
+	}
+
+	// SQLite date functions normalize the stored timezone offset to UTC
+	switch granularity {
+	case GranularityHour:
+		return "strftime('%Y-%m-%d %H:00:00', timestamp)", nil
+	case GranularityDay:
+		return "strftime('%Y-%m-%d 00:00:00', timestamp)", nil
+	case GranularityWeek:
+		// Move forward to the next Sunday (or stay on it), then back 6 days to reach Monday
+		return "strftime('%Y-%m-%d 00:00:00', timestamp, 'weekday 0', '-6 days')", nil
+	}
+	return "", fmt.Errorf("unsupported granularity '%s'", granularity)
+}
+
+// CountClicksByUserAgent counts the clicks of a link grouped by raw User-Agent string.
+// Grouping in SQL keeps the result small: there are far fewer distinct user agents than clicks.
+// Parameters:
+//   - linkID: the database ID of the link
+//
+// Returns:
+//   - []GroupCount: one entry per distinct user agent, most frequent first
+//   - error: nil on success, or database error if query fails
+func (r *GormClickRepository) CountClicksByUserAgent(linkID uint) ([]GroupCount, error) {
+	var counts []GroupCount
+	err := r.db.Model(&models.Click{}).
+		Select("user_agent AS value, COUNT(*) AS count").
+		Where("link_id = ?", linkID).
+		Group("user_agent").
+		Order("count DESC").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to group clicks by user agent for link ID %d: %w", linkID, err)
+	}
+	return counts, nil
+}
+
+// CountClicksByCountry counts the clicks of a link grouped by country code.
+// Clicks recorded before the country column existed hold NULL, so they are merged with the empty value.
+// Parameters:
+//   - linkID: the database ID of the link
+//
+// Returns:
+//   - []GroupCount: one entry per country (empty value for unresolved IPs), most frequent first
+//   - error: nil on success, or database error if query fails
+func (r *GormClickRepository) CountClicksByCountry(linkID uint) ([]GroupCount, error) {
+	var counts []GroupCount
+	err := r.db.Model(&models.Click{}).
+		Select("COALESCE(country, '') AS value, COUNT(*) AS count").
+		Where("link_id = ?", linkID).
+		Group("COALESCE(country, '')").
+		Order("count DESC").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to group clicks by country for link ID %d: %w", linkID, err)
+	}
+	return counts, nil
+}
+
+// CountClicksByReferrer counts the clicks of a link grouped by referrer host.
+// Clicks recorded before the referrer column existed hold NULL, so they are merged with direct traffic.
+// Parameters:
+//   - linkID: the database ID of the link
+//   - limit: maximum number of referrers to return
+//
+// Returns:
+//   - []GroupCount: the top referrers (empty value for direct traffic), most frequent first
+//   - error: nil on success, or database error if query fails
+func (r *GormClickRepository) CountClicksByReferrer(linkID uint, limit int) ([]GroupCount, error) {
+	var counts []GroupCount
+	err := r.db.Model(&models.Click{}).
+		Select("COALESCE(referrer, '') AS value, COUNT(*) AS count").
+		Where("link_id = ?", linkID).
+		Group("COALESCE(referrer, '')").
+		Order("count DESC").
+		Limit(limit).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to group clicks by referrer for link ID %d: %w", linkID, err)
+	}
+	return counts, nil
+}
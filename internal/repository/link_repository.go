@@ -2,6 +2,8 @@ package repository
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/axellelanca/urlshortener/internal/models"
 	"gorm.io/gorm"
@@ -15,19 +17,78 @@ type LinkRepository interface {
 	// Used when users create new short URLs via API or CLI.
 	CreateLink(link *models.Link) error
 
-	// GetLinkByShortCode retrieves a link record using its unique short code.
+	// GetLinkByShortCode retrieves a link record using its short code within a namespace
+	// ("" for the global namespace, the workspace slug for namespaced workspaces).
 	// This is the primary method used during URL redirection to find the target URL.
-	GetLinkByShortCode(shortCode string) (*models.Link, error)
+	GetLinkByShortCode(namespace, shortCode string) (*models.Link, error)
 
-	// GetAllLinks retrieves all link records from the database.
-	// Used by the URL monitor to check the health of all registered URLs.
-	GetAllLinks() ([]models.Link, error)
+	// ShortCodeExists reports whether a short code is used in a namespace by any link, including soft-deleted ones.
+	// Used when allocating codes so that a deleted link's code is never handed out again.
+	ShortCodeExists(namespace, shortCode string) (bool, error)
+
+	// FindLinksByLongURLHash returns the live links of a workspace whose destination has the given hash, oldest first.
+	// Used to return an existing link instead of creating a duplicate when deduplication is requested.
+	FindLinksByLongURLHash(workspace, longURLHash string) ([]models.Link, error)
+
+	// UpdateLink saves the given columns of an existing link (e.g. "long_url", "disabled").
+	// Only these columns are written, so concurrent changes to the other ones (such as a monitor check) are kept.
+	// Used to change the target URL or to disable/enable a link.
+	UpdateLink(link *models.Link, columns ...string) error
+
+	// DeleteLink soft-deletes a link so it stops resolving while its click history is kept.
+	DeleteLink(link *models.Link) error
+
+	// ListLinks retrieves one page of links matching the query, together with their click counts.
+	// Used by the list endpoint/command and by the URL monitor to walk the table page by page.
+	ListLinks(query LinkListQuery) ([]LinkWithClicks, error)
+
+	// UpdateHealthStatus records the result of a URL monitor check for a link.
+	UpdateHealthStatus(linkID uint, status string, checkedAt time.Time) error
 
 	// CountClicksByLinkID returns the total number of clicks for a specific link.
 	// Used for generating statistics and analytics reports.
 	CountClicksByLinkID(linkID uint) (int, error)
 }
 
+// Sort keys accepted by LinkListQuery.SortBy.
+const (
+	SortByCreatedAt = "created_at" // Order by creation date
+	SortByClicks    = "clicks"     // Order by total number of clicks
+)
+
+// LinkListFilter narrows down the links returned by ListLinks.
+// Zero-valued fields are ignored.
+type LinkListFilter struct {
+	Workspace     string     // Only links owned by this workspace; empty means every workspace
+	Domain        string     // Destination host; also matches its subdomains (e.g. "example.com" matches "www.example.com")
+	CreatedAfter  *time.Time // Only links created at or after this time
+	CreatedBefore *time.Time // Only links created strictly before this time
+	HealthStatus  string     // Only links whose last monitor check has this status
+}
+
+// LinkCursor identifies the last link of a page for keyset pagination.
+// Only the field matching the query's SortBy is used, with ID as a tie-breaker.
+type LinkCursor struct {
+	CreatedAt time.Time
+	Clicks    int
+	ID        uint
+}
+
+// LinkListQuery describes one page of a link listing.
+type LinkListQuery struct {
+	Filter     LinkListFilter
+	SortBy     string      // SortByCreatedAt or SortByClicks
+	Descending bool        // Sort direction
+	Limit      int         // Maximum number of links to return
+	After      *LinkCursor // Return links strictly after this position; nil for the first page
+}
+
+// LinkWithClicks is a link together with its total number of recorded clicks.
+type LinkWithClicks struct {
+	models.Link
+	ClickCount int
+}
+
 // GormLinkRepository is the GORM-based implementation of LinkRepository interface.
 // It uses GORM ORM to provide a high-level interface to the underlying SQLite database.
 type GormLinkRepository struct {
@@ -52,7 +113,8 @@ func NewLinkRepository(db *gorm.DB) *GormLinkRepository {
 //   - link: pointer to the Link model containing short code, long URL, and metadata
 //
 // Returns:
-//   - error: nil on success, or database error if insertion fails (e.g., duplicate short code)
+//   - error: nil on success, gorm.ErrDuplicatedKey (wrapped) if the short code is already used in the namespace,
+//     or database error if insertion fails
 func (r *GormLinkRepository) CreateLink(link *models.Link) error {
 	if err := r.db.Create(link).Error; err != nil {
 		return fmt.Errorf("failed to create link: %w", err)
@@ -64,37 +126,186 @@ func (r *GormLinkRepository) CreateLink(link *models.Link) error {
 // This is the most frequently called method, used during URL redirection to find
 // the original long URL associated with a short code (e.g., "abc123" -> "https://google.com").
 // Parameters:
-//   - shortCode: the unique short code identifier to search for
+//   - namespace: the namespace of the short code ("" for the global namespace)
+//   - shortCode: the short code identifier to search for
 //
 // Returns:
 //   - *models.Link: pointer to the found link record with all its data
 //   - error: gorm.ErrRecordNotFound if short code doesn't exist, or other database errors
-func (r *GormLinkRepository) GetLinkByShortCode(shortCode string) (*models.Link, error) {
+func (r *GormLinkRepository) GetLinkByShortCode(namespace, shortCode string) (*models.Link, error) {
 	var link models.Link
-	// Use GORM's Where() to filter by short_code column and First() to get single result
+	// Use GORM's Where() to filter by the (namespace, short_code) unique index and First() to get single result
 	// First() returns ErrRecordNotFound if no matching record exists
-	if err := r.db.Where("short_code = ?", shortCode).First(&link).Error; err != nil {
+	if err := r.db.Where("namespace = ? AND short_code = ?", namespace, shortCode).First(&link).Error; err != nil {
 		return nil, err
 	}
 	return &link, nil
 }
 
-// GetAllLinks retrieves all link records from the database.
-// This method is primarily used by the URL monitoring system to periodically
-// check the health status of all registered URLs. It returns all links without pagination.
+// ShortCodeExists checks whether a short code is already used by a link of a namespace.
+// Soft-deleted links are included (via Unscoped) because their rows still hold the unique index entry,
+// and because reusing a deleted code would silently send old bookmarks to a new destination.
+// Parameters:
+//   - namespace: the namespace to look in ("" for the global namespace)
+//   - shortCode: the short code to look for
+//
 // Returns:
-//   - []models.Link: slice containing all link records in the database
+//   - bool: true if any link of the namespace, deleted or not, uses this short code
 //   - error: nil on success, or database error if query fails
-func (r *GormLinkRepository) GetAllLinks() ([]models.Link, error) {
+func (r *GormLinkRepository) ShortCodeExists(namespace, shortCode string) (bool, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&models.Link{}).
+		Where("namespace = ? AND short_code = ?", namespace, shortCode).Count(&count).Error
+	if err != nil {
+		return false, fmt.Errorf("failed to check short code %s: %w", shortCode, err)
+	}
+	return count > 0, nil
+}
+
+// FindLinksByLongURLHash retrieves the non-deleted links of a workspace sharing a destination.
+// The (workspace, long_url_hash) index makes this lookup cheap even on large tables.
+// Parameters:
+//   - workspace: the slug of the workspace owning the links
+//   - longURLHash: the hash of the normalized destination (see urlnorm.Hash)
+//
+// Returns:
+//   - []models.Link: matching links ordered by ID, oldest first; empty if none
+//   - error: nil on success, or database error if query fails
+func (r *GormLinkRepository) FindLinksByLongURLHash(workspace, longURLHash string) ([]models.Link, error) {
 	var links []models.Link
-	// Use GORM's Find() to retrieve all records from the links table
-	// Find() populates the slice with all matching records
-	if err := r.db.Find(&links).Error; err != nil {
-		return nil, fmt.Errorf("failed to retrieve all links: %w", err)
+	err := r.db.Where("workspace = ? AND long_url_hash = ?", workspace, longURLHash).Order("id ASC").Find(&links).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to find links by long URL hash: %w", err)
+	}
+	return links, nil
+}
+
+// UpdateLink writes the given columns of an existing link and refreshes its UpdatedAt timestamp.
+// The other columns are left untouched, so a link loaded before a concurrent change (another process,
+// the URL monitor) does not put stale values back.
+// Parameters:
+//   - link: pointer to the modified Link model
+//   - columns: the database columns to write, e.g. "long_url" or "disabled"
+//
+// Returns:
+//   - error: nil on success, or database error if the update fails
+func (r *GormLinkRepository) UpdateLink(link *models.Link, columns ...string) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("failed to update link %s: no column to update", link.ShortCode)
+	}
+	// Select() makes Updates() write zero values too (e.g. disabled=false) and nothing else
+	if err := r.db.Model(link).Select(append(columns, "updated_at")).Updates(link).Error; err != nil {
+		return fmt.Errorf("failed to update link %s: %w", link.ShortCode, err)
+	}
+	return nil
+}
+
+// DeleteLink soft-deletes a link by setting its DeletedAt column.
+// The row and all its clicks stay in the database so historical statistics are preserved.
+// Parameters:
+//   - link: pointer to the Link model to delete
+//
+// Returns:
+//   - error: nil on success, or database error if the delete fails
+func (r *GormLinkRepository) DeleteLink(link *models.Link) error {
+	if err := r.db.Delete(link).Error; err != nil {
+		return fmt.Errorf("failed to delete link %s: %w", link.ShortCode, err)
+	}
+	return nil
+}
+
+// ListLinks retrieves one page of links using keyset (cursor) pagination.
+// Each link is returned with its click count, computed by a correlated subquery so that
+// links can be sorted and paginated by popularity. The whole query is wrapped in a derived
+// table so the computed click_count can be used in WHERE and ORDER BY clauses.
+// Parameters:
+//   - query: filters, sort order, page size and the cursor of the previous page
+//
+// Returns:
+//   - []LinkWithClicks: at most query.Limit links in the requested order
+//   - error: nil on success, or database error if query fails
+func (r *GormLinkRepository) ListLinks(query LinkListQuery) ([]LinkWithClicks, error) {
+	// Inner query: non-deleted links matching the filters, with their click counts
+	inner := r.db.Model(&models.Link{}).
+		Select("links.*, (SELECT COUNT(*) FROM clicks WHERE clicks.link_id = links.id) AS click_count")
+
+	filter := query.Filter
+	if filter.Workspace != "" {
+		inner = inner.Where("links.workspace = ?", filter.Workspace)
+	}
+	if filter.Domain != "" {
+		// The domain is user input: its LIKE wildcards must match literally (see escapeLike)
+		inner = inner.Where(`links.domain = ? OR links.domain LIKE ? ESCAPE '\'`, filter.Domain, "%."+escapeLike(filter.Domain))
+	}
+	if filter.CreatedAfter != nil {
+		inner = inner.Where("links.created_at >= ?", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		inner = inner.Where("links.created_at < ?", *filter.CreatedBefore)
+	}
+	if filter.HealthStatus != "" {
+		inner = inner.Where("links.health_status = ?", filter.HealthStatus)
+	}
+
+	// Outer query: keyset pagination and ordering on the sort column, with id as tie-breaker
+	sortColumn := "created_at"
+	if query.SortBy == SortByClicks {
+		sortColumn = "click_count"
+	}
+	comparator, direction := ">", "ASC"
+	if query.Descending {
+		comparator, direction = "<", "DESC"
+	}
+
+	outer := r.db.Table("(?) AS l", inner)
+	if query.After != nil {
+		var cursorValue interface{} = query.After.CreatedAt
+		if query.SortBy == SortByClicks {
+			cursorValue = query.After.Clicks
+		}
+		outer = outer.Where(
+			fmt.Sprintf("%[1]s %[2]s ? OR (%[1]s = ? AND id %[2]s ?)", sortColumn, comparator),
+			cursorValue, cursorValue, query.After.ID,
+		)
+	}
+
+	var links []LinkWithClicks
+	err := outer.
+		Order(fmt.Sprintf("%s %s, id %s", sortColumn, direction, direction)).
+		Limit(query.Limit).
+		Find(&links).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to list links: %w", err)
 	}
 	return links, nil
 }
 
+// likeEscaper escapes the LIKE wildcards and the escape character itself, for use with ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// escapeLike makes a value match literally inside a LIKE pattern, so "_xample.com" does not match "example.com".
+func escapeLike(value string) string {
+	return likeEscaper.Replace(value)
+}
+
+// UpdateHealthStatus stores the result of a URL monitor check.
+// Only the health columns are written so concurrent edits of the link are not overwritten.
+// Parameters:
+//   - linkID: the database ID of the checked link
+//   - status: models.HealthUp or models.HealthDown
+//   - checkedAt: when the check was performed
+//
+// Returns:
+//   - error: nil on success, or database error if the update fails
+func (r *GormLinkRepository) UpdateHealthStatus(linkID uint, status string, checkedAt time.Time) error {
+	err := r.db.Model(&models.Link{}).Where("id = ?", linkID).
+		UpdateColumns(map[string]interface{}{"health_status": status, "last_checked_at": checkedAt}).Error
+	if err != nil {
+		return fmt.Errorf("failed to update health status for link ID %d: %w", linkID, err)
+	}
+	return nil
+}
+
 // CountClicksByLinkID counts the total number of clicks for a given link ID.
 // This method provides analytics data by counting click records associated with a link.
 // It performs a SQL COUNT query on the clicks table filtered by link_id.
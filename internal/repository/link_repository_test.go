@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/config"
+	"github.com/axellelanca/urlshortener/internal/database"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// newTestDB opens a migrated, private in-memory SQLite database.
+// A single connection is used because every new connection to ":memory:" would see an empty database.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Database.Driver = database.DriverSQLite
+	cfg.Database.DSN = ":memory:"
+	cfg.Database.MaxOpenConns = 1
+
+	db, err := database.Open(cfg)
+	if err != nil {
+		t.Fatalf("failed to open test database: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	if err := database.AutoMigrate(db); err != nil {
+		t.Fatalf("failed to migrate test database: %v", err)
+	}
+	return db
+}
+
+// linkBackends returns the link repository implementations that must behave identically,
+// each with a click repository sharing its data.
+func linkBackends(t *testing.T) map[string]func() (LinkRepository, ClickRepository) {
+	return map[string]func() (LinkRepository, ClickRepository){
+		"gorm": func() (LinkRepository, ClickRepository) {
+			db := newTestDB(t)
+			return NewLinkRepository(db), NewClickRepository(db)
+		},
+		"memory": func() (LinkRepository, ClickRepository) {
+			store := NewMemoryStore()
+			return NewMemoryLinkRepository(store), NewMemoryClickRepository(store)
+		},
+	}
+}
+
+// createTestLink stores a link of the default workspace pointing to a page of the given host.
+func createTestLink(t *testing.T, repo LinkRepository, shortCode, host string, createdAt time.Time) *models.Link {
+	t.Helper()
+	longURL := "https://" + host + "/" + shortCode
+	link := &models.Link{
+		ShortCode:    shortCode,
+		Workspace:    models.DefaultWorkspace,
+		LongURL:      longURL,
+		Domain:       models.DomainOf(longURL),
+		CreatedAt:    createdAt,
+		HealthStatus: models.HealthUnknown,
+	}
+	if err := repo.CreateLink(link); err != nil {
+		t.Fatalf("failed to create link %s: %v", shortCode, err)
+	}
+	return link
+}
+
+// listAllPages walks a listing page by page through the keyset cursor and returns the short codes in order.
+func listAllPages(t *testing.T, repo LinkRepository, query LinkListQuery) []string {
+	t.Helper()
+	var codes []string
+	for pages := 0; ; pages++ {
+		if pages > 20 {
+			t.Fatal("pagination does not terminate")
+		}
+		links, err := repo.ListLinks(query)
+		if err != nil {
+			t.Fatalf("ListLinks failed: %v", err)
+		}
+		for _, link := range links {
+			codes = append(codes, link.ShortCode)
+		}
+		if len(links) < query.Limit {
+			return codes
+		}
+		last := links[len(links)-1]
+		query.After = &LinkCursor{CreatedAt: last.CreatedAt, Clicks: last.ClickCount, ID: last.ID}
+	}
+}
+
+func TestListLinksCursorPagination(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for name, newBackend := range linkBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			linkRepo, clickRepo := newBackend()
+
+			// "b" and "c" share a creation time so the ID tie-breaker decides their order
+			createdAt := []time.Duration{0, time.Hour, time.Hour, 2 * time.Hour, 3 * time.Hour}
+			clicks := []int{2, 0, 2, 5, 1}
+			for i, offset := range createdAt {
+				link := createTestLink(t, linkRepo, string(rune('a'+i)), "example.com", base.Add(offset))
+				for j := 0; j < clicks[i]; j++ {
+					if err := clickRepo.CreateClick(&models.Click{LinkID: link.ID, Timestamp: base}); err != nil {
+						t.Fatalf("failed to create click: %v", err)
+					}
+				}
+			}
+
+			tests := []struct {
+				sortBy     string
+				descending bool
+				want       string
+			}{
+				{SortByCreatedAt, true, "edcba"},
+				{SortByCreatedAt, false, "abcde"},
+				{SortByClicks, true, "dcaeb"},
+				{SortByClicks, false, "beacd"},
+			}
+			for _, tt := range tests {
+				for _, limit := range []int{1, 2, 5} {
+					query := LinkListQuery{SortBy: tt.sortBy, Descending: tt.descending, Limit: limit}
+					got := fmt.Sprint(listAllPages(t, linkRepo, query))
+					want := fmt.Sprint(splitCodes(tt.want))
+					if got != want {
+						t.Errorf("sort=%s desc=%v limit=%d: got %s, want %s", tt.sortBy, tt.descending, limit, got, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestListLinksDomainFilter(t *testing.T) {
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	for name, newBackend := range linkBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			linkRepo, _ := newBackend()
+			createTestLink(t, linkRepo, "apex", "example.com", base)
+			createTestLink(t, linkRepo, "sub", "sub.example.com", base.Add(time.Minute))
+			createTestLink(t, linkRepo, "other", "notexample.com", base.Add(2*time.Minute))
+
+			tests := []struct {
+				domain string
+				want   string
+			}{
+				{"example.com", "[apex sub]"},
+				{"sub.example.com", "[sub]"},
+				// LIKE wildcards in the filter must match literally
+				{"_xample.com", "[]"},
+				{"%.com", "[]"},
+			}
+			for _, tt := range tests {
+				query := LinkListQuery{Filter: LinkListFilter{Domain: tt.domain}, SortBy: SortByCreatedAt, Limit: 10}
+				if got := fmt.Sprint(listAllPages(t, linkRepo, query)); got != tt.want {
+					t.Errorf("domain=%q: got %s, want %s", tt.domain, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+// splitCodes turns "abc" into ["a" "b" "c"].
+func splitCodes(codes string) []string {
+	split := make([]string, 0, len(codes))
+	for _, code := range codes {
+		split = append(split, string(code))
+	}
+	return split
+}
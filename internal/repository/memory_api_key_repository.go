@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// MemoryAPIKeyRepository is the thread-safe in-memory implementation of the APIKeyRepository interface.
+// Used by the server's ephemeral mode, where the keys only live as long as the process.
+type MemoryAPIKeyRepository struct {
+	store *MemoryStore // Shared in-memory data
+}
+
+// NewMemoryAPIKeyRepository creates and returns a new instance of MemoryAPIKeyRepository.
+// Parameters:
+//   - store: in-memory store holding the keys
+//
+// Returns:
+//   - *MemoryAPIKeyRepository: configured repository instance ready for use
+func NewMemoryAPIKeyRepository(store *MemoryStore) *MemoryAPIKeyRepository {
+	return &MemoryAPIKeyRepository{store: store}
+}
+
+// CreateAPIKey stores a new API key, assigning its ID and creation time like the database would.
+func (r *MemoryAPIKeyRepository) CreateAPIKey(key *models.APIKey) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for i := range r.store.apiKeys {
+		if r.store.apiKeys[i].KeyHash == key.KeyHash {
+			return fmt.Errorf("failed to create API key: hash already exists")
+		}
+	}
+
+	key.ID = r.store.nextAPIKeyID
+	r.store.nextAPIKeyID++
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now()
+	}
+	if key.Workspace == "" {
+		key.Workspace = models.DefaultWorkspace
+	}
+	r.store.apiKeys = append(r.store.apiKeys, *key)
+	return nil
+}
+
+// GetAPIKeyByHash retrieves an API key from the hash of its plain value.
+// Returns gorm.ErrRecordNotFound if no key has this hash.
+func (r *MemoryAPIKeyRepository) GetAPIKeyByHash(keyHash string) (*models.APIKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for i := range r.store.apiKeys {
+		if r.store.apiKeys[i].KeyHash == keyHash {
+			key := r.store.apiKeys[i]
+			return &key, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// GetAPIKeyByID retrieves an API key by its ID.
+// Returns gorm.ErrRecordNotFound if no key has this ID.
+func (r *MemoryAPIKeyRepository) GetAPIKeyByID(id uint) (*models.APIKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	if i := r.store.findAPIKey(id); i >= 0 {
+		key := r.store.apiKeys[i]
+		return &key, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ListAPIKeys returns a copy of every API key ordered by ID.
+func (r *MemoryAPIKeyRepository) ListAPIKeys() ([]models.APIKey, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return append([]models.APIKey(nil), r.store.apiKeys...), nil
+}
+
+// RevokeAPIKey sets the revocation date of a key. Unknown IDs are ignored, like an UPDATE matching no row.
+func (r *MemoryAPIKeyRepository) RevokeAPIKey(id uint, revokedAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if i := r.store.findAPIKey(id); i >= 0 {
+		r.store.apiKeys[i].RevokedAt = &revokedAt
+	}
+	return nil
+}
+
+// TouchAPIKey sets the last usage date of a key. Unknown IDs are ignored, like an UPDATE matching no row.
+func (r *MemoryAPIKeyRepository) TouchAPIKey(id uint, usedAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if i := r.store.findAPIKey(id); i >= 0 {
+		r.store.apiKeys[i].LastUsedAt = &usedAt
+	}
+	return nil
+}
+
+// findAPIKey returns the index of the API key with the given ID, or -1.
+// Callers must hold the store lock.
+func (s *MemoryStore) findAPIKey(id uint) int {
+	for i := range s.apiKeys {
+		if s.apiKeys[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,201 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// MemoryClickRepository is the thread-safe in-memory implementation of the ClickRepository interface.
+// Aggregations are computed by scanning the stored clicks, which is fine for tests and throwaway instances.
+type MemoryClickRepository struct {
+	store *MemoryStore // Shared in-memory data
+}
+
+// NewMemoryClickRepository creates and returns a new instance of MemoryClickRepository.
+// Parameters:
+//   - store: in-memory store holding the clicks (shared with the link repository)
+//
+// Returns:
+//   - *MemoryClickRepository: configured repository instance ready for use
+func NewMemoryClickRepository(store *MemoryStore) *MemoryClickRepository {
+	return &MemoryClickRepository{store: store}
+}
+
+// CreateClick stores a new click and assigns its ID.
+// Parameters:
+//   - click: pointer to the Click model containing all click event data
+//
+// Returns:
+//   - error: always nil
+func (r *MemoryClickRepository) CreateClick(click *models.Click) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	click.ID = r.store.nextClickID
+	r.store.nextClickID++
+	r.store.clicks = append(r.store.clicks, *click)
+	return nil
+}
+
+// CreateClicks stores a batch of clicks and assigns their IDs.
+// Parameters:
+//   - clicks: the Click models to store
+//
+// Returns:
+//   - error: always nil
+func (r *MemoryClickRepository) CreateClicks(clicks []models.Click) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for i := range clicks {
+		clicks[i].ID = r.store.nextClickID
+		r.store.nextClickID++
+		r.store.clicks = append(r.store.clicks, clicks[i])
+	}
+	return nil
+}
+
+// CountClicksByLinkID counts the clicks recorded for a link.
+// Parameters:
+//   - linkID: the ID of the link to count clicks for
+//
+// Returns:
+//   - int: total number of clicks recorded for this link
+//   - error: always nil
+func (r *MemoryClickRepository) CountClicksByLinkID(linkID uint) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.countClicks(linkID), nil
+}
+
+// CountUniqueVisitorsByLinkID counts the distinct non-empty visitor hashes of a link.
+// Parameters:
+//   - linkID: the ID of the link to count visitors for
+//
+// Returns:
+//   - int: number of distinct non-empty visitor hashes
+//   - error: always nil
+func (r *MemoryClickRepository) CountUniqueVisitorsByLinkID(linkID uint) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	visitors := make(map[string]struct{})
+	for _, click := range r.store.clicks {
+		if click.LinkID == linkID && click.VisitorHash != "" {
+			visitors[click.VisitorHash] = struct{}{}
+		}
+	}
+	return len(visitors), nil
+}
+
+// CountClicksByInterval counts the clicks of a link per hour, day or week (starting on Monday) within [from, to).
+// Parameters:
+//   - linkID: the ID of the link
+//   - granularity: GranularityHour, GranularityDay or GranularityWeek
+//   - from: inclusive lower bound of the click timestamps
+//   - to: exclusive upper bound of the click timestamps
+//
+// Returns:
+//   - []ClickBucket: non-empty buckets in chronological order
+//   - error: if the granularity is not supported
+func (r *MemoryClickRepository) CountClicksByInterval(linkID uint, granularity string, from, to time.Time) ([]ClickBucket, error) {
+	switch granularity {
+	case GranularityHour, GranularityDay, GranularityWeek:
+	default:
+		return nil, fmt.Errorf("unsupported granularity '%s'", granularity)
+	}
+
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	counts := make(map[time.Time]int)
+	for _, click := range r.store.clicks {
+		if click.LinkID != linkID || click.Timestamp.Before(from) || !click.Timestamp.Before(to) {
+			continue
+		}
+		t := click.Timestamp.UTC()
+		start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		switch granularity {
+		case GranularityHour:
+			start = start.Add(time.Duration(t.Hour()) * time.Hour)
+		case GranularityWeek:
+			// time.Weekday counts from Sunday (0); shift so Monday is 0
+			start = start.AddDate(0, 0, -((int(start.Weekday()) + 6) % 7))
+		}
+		counts[start]++
+	}
+
+	buckets := make([]ClickBucket, 0, len(counts))
+	for start, count := range counts {
+		buckets = append(buckets, ClickBucket{Start: start, Count: count})
+	}
+	sort.Slice(buckets, func(i, j int) bool { return buckets[i].Start.Before(buckets[j].Start) })
+	return buckets, nil
+}
+
+// CountClicksByUserAgent counts the clicks of a link grouped by raw User-Agent string.
+// Parameters:
+//   - linkID: the ID of the link
+//
+// Returns:
+//   - []GroupCount: one entry per distinct user agent, most frequent first
+//   - error: always nil
+func (r *MemoryClickRepository) CountClicksByUserAgent(linkID uint) ([]GroupCount, error) {
+	return r.groupClicks(linkID, 0, func(click models.Click) string { return click.UserAgent }), nil
+}
+
+// CountClicksByCountry counts the clicks of a link grouped by country code.
+// Parameters:
+//   - linkID: the ID of the link
+//
+// Returns:
+//   - []GroupCount: one entry per country (empty value for unresolved IPs), most frequent first
+//   - error: always nil
+func (r *MemoryClickRepository) CountClicksByCountry(linkID uint) ([]GroupCount, error) {
+	return r.groupClicks(linkID, 0, func(click models.Click) string { return click.Country }), nil
+}
+
+// CountClicksByReferrer counts the clicks of a link grouped by referrer host.
+// Parameters:
+//   - linkID: the ID of the link
+//   - limit: maximum number of referrers to return
+//
+// Returns:
+//   - []GroupCount: the top referrers (empty value for direct traffic), most frequent first
+//   - error: always nil
+func (r *MemoryClickRepository) CountClicksByReferrer(linkID uint, limit int) ([]GroupCount, error) {
+	return r.groupClicks(linkID, limit, func(click models.Click) string { return click.Referrer }), nil
+}
+
+// groupClicks counts the clicks of a link per value of the given field, most frequent first
+// (ties ordered by value so results are stable), keeping at most limit entries when limit > 0.
+func (r *MemoryClickRepository) groupClicks(linkID uint, limit int, field func(models.Click) string) []GroupCount {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	countByValue := make(map[string]int)
+	for _, click := range r.store.clicks {
+		if click.LinkID == linkID {
+			countByValue[field(click)]++
+		}
+	}
+
+	counts := make([]GroupCount, 0, len(countByValue))
+	for value, count := range countByValue {
+		counts = append(counts, GroupCount{Value: value, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Value < counts[j].Value
+	})
+	if limit > 0 && len(counts) > limit {
+		counts = counts[:limit]
+	}
+	return counts
+}
@@ -0,0 +1,348 @@
+package repository
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// MemoryStore holds links and clicks in memory for the in-memory repositories.
+// It plays the role of the *gorm.DB shared by the GORM repositories: a link repository and a click
+// repository built on the same store see each other's data (e.g. click counts when listing links).
+// Nothing is persisted; the data disappears with the process.
+type MemoryStore struct {
+	mu              sync.RWMutex       // Guards every field below
+	links           []models.Link      // All links, including soft-deleted ones, in insertion (ID) order
+	clicks          []models.Click     // All recorded clicks, in insertion (ID) order
+	apiKeys         []models.APIKey    // All API keys, including revoked ones, in insertion (ID) order
+	workspaces      []models.Workspace // All workspaces, in insertion (ID) order
+	nextLinkID      uint               // Next auto-increment ID handed out to a link
+	nextClickID     uint               // Next auto-increment ID handed out to a click
+	nextAPIKeyID    uint               // Next auto-increment ID handed out to an API key
+	nextWorkspaceID uint               // Next auto-increment ID handed out to a workspace
+}
+
+// NewMemoryStore creates an in-memory store holding only the default workspace, like a freshly migrated database.
+// Returns:
+//   - *MemoryStore: store ready to be shared by NewMemoryLinkRepository, NewMemoryClickRepository,
+//     NewMemoryAPIKeyRepository and NewMemoryWorkspaceRepository
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		workspaces:      []models.Workspace{{ID: 1, Slug: models.DefaultWorkspace, Name: "Default workspace", CreatedAt: time.Now()}},
+		nextLinkID:      1,
+		nextClickID:     1,
+		nextAPIKeyID:    1,
+		nextWorkspaceID: 2,
+	}
+}
+
+// findLink returns the index of the link with the given ID, or -1.
+// Callers must hold the store lock.
+func (s *MemoryStore) findLink(id uint) int {
+	for i := range s.links {
+		if s.links[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// countClicks returns the number of clicks recorded for a link.
+// Callers must hold the store lock.
+func (s *MemoryStore) countClicks(linkID uint) int {
+	count := 0
+	for i := range s.clicks {
+		if s.clicks[i].LinkID == linkID {
+			count++
+		}
+	}
+	return count
+}
+
+// MemoryLinkRepository is the thread-safe in-memory implementation of the LinkRepository interface.
+// It mirrors the behaviour of GormLinkRepository (soft deletes, gorm.ErrRecordNotFound, keyset pagination)
+// so services behave identically on top of it. Used by integration tests and the server's ephemeral mode.
+type MemoryLinkRepository struct {
+	store *MemoryStore // Shared in-memory data
+}
+
+// NewMemoryLinkRepository creates and returns a new instance of MemoryLinkRepository.
+// Parameters:
+//   - store: in-memory store holding the links (shared with the click repository)
+//
+// Returns:
+//   - *MemoryLinkRepository: configured repository instance ready for use
+func NewMemoryLinkRepository(store *MemoryStore) *MemoryLinkRepository {
+	return &MemoryLinkRepository{store: store}
+}
+
+// CreateLink stores a new link, assigning its ID and timestamps like the database would.
+// Parameters:
+//   - link: pointer to the Link model to store; its ID, CreatedAt and UpdatedAt are filled in
+//
+// Returns:
+//   - error: nil on success, or gorm.ErrDuplicatedKey if the short code is already used (even by a deleted link)
+func (r *MemoryLinkRepository) CreateLink(link *models.Link) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for i := range r.store.links {
+		if r.store.links[i].Namespace == link.Namespace && r.store.links[i].ShortCode == link.ShortCode {
+			return fmt.Errorf("failed to create link: short code %s already exists: %w", link.Path(), gorm.ErrDuplicatedKey)
+		}
+	}
+
+	now := time.Now()
+	link.ID = r.store.nextLinkID
+	r.store.nextLinkID++
+	if link.CreatedAt.IsZero() {
+		link.CreatedAt = now
+	}
+	if link.UpdatedAt.IsZero() {
+		link.UpdatedAt = now
+	}
+	if link.HealthStatus == "" {
+		link.HealthStatus = models.HealthUnknown
+	}
+	if link.Workspace == "" {
+		link.Workspace = models.DefaultWorkspace
+	}
+	r.store.links = append(r.store.links, *link)
+	return nil
+}
+
+// GetLinkByShortCode retrieves a non-deleted link using its short code within a namespace.
+// Parameters:
+//   - namespace: the namespace of the short code ("" for the global namespace)
+//   - shortCode: the short code identifier to search for
+//
+// Returns:
+//   - *models.Link: a copy of the stored link
+//   - error: gorm.ErrRecordNotFound if no live link uses this short code
+func (r *MemoryLinkRepository) GetLinkByShortCode(namespace, shortCode string) (*models.Link, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for i := range r.store.links {
+		link := &r.store.links[i]
+		if link.Namespace == namespace && link.ShortCode == shortCode && !link.DeletedAt.Valid {
+			found := *link
+			return &found, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ShortCodeExists checks whether a short code is already used by a link of a namespace, including soft-deleted ones.
+// Parameters:
+//   - namespace: the namespace to look in ("" for the global namespace)
+//   - shortCode: the short code to look for
+//
+// Returns:
+//   - bool: true if any link of the namespace, deleted or not, uses this short code
+//   - error: always nil
+func (r *MemoryLinkRepository) ShortCodeExists(namespace, shortCode string) (bool, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for i := range r.store.links {
+		if r.store.links[i].Namespace == namespace && r.store.links[i].ShortCode == shortCode {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// FindLinksByLongURLHash retrieves the non-deleted links of a workspace sharing a destination.
+// Parameters:
+//   - workspace: the slug of the workspace owning the links
+//   - longURLHash: the hash of the normalized destination (see urlnorm.Hash)
+//
+// Returns:
+//   - []models.Link: copies of the matching links in ID order, oldest first
+//   - error: always nil
+func (r *MemoryLinkRepository) FindLinksByLongURLHash(workspace, longURLHash string) ([]models.Link, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	var links []models.Link
+	for i := range r.store.links {
+		link := &r.store.links[i]
+		if link.Workspace == workspace && link.LongURLHash == longURLHash && !link.DeletedAt.Valid {
+			links = append(links, *link)
+		}
+	}
+	return links, nil
+}
+
+// UpdateLink copies the given columns of the link into the stored one and refreshes UpdatedAt,
+// leaving the other fields as they are in the store like GormLinkRepository.UpdateLink.
+// Parameters:
+//   - link: pointer to the modified Link model
+//   - columns: the database columns to write, among those of linkColumnSetters
+//
+// Returns:
+//   - error: nil on success, an error for an unknown column, or an error wrapping gorm.ErrRecordNotFound if the link does not exist
+func (r *MemoryLinkRepository) UpdateLink(link *models.Link, columns ...string) error {
+	if len(columns) == 0 {
+		return fmt.Errorf("failed to update link %s: no column to update", link.ShortCode)
+	}
+	for _, column := range columns {
+		if _, ok := linkColumnSetters[column]; !ok {
+			return fmt.Errorf("failed to update link %s: unknown column %s", link.ShortCode, column)
+		}
+	}
+
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	i := r.store.findLink(link.ID)
+	if i < 0 {
+		return fmt.Errorf("failed to update link %s: %w", link.ShortCode, gorm.ErrRecordNotFound)
+	}
+	for _, column := range columns {
+		linkColumnSetters[column](&r.store.links[i], link)
+	}
+	link.UpdatedAt = time.Now()
+	r.store.links[i].UpdatedAt = link.UpdatedAt
+	return nil
+}
+
+// linkColumnSetters copies one updatable column from a modified link (src) to the stored one (dst).
+var linkColumnSetters = map[string]func(dst, src *models.Link){
+	"long_url":        func(dst, src *models.Link) { dst.LongURL = src.LongURL },
+	"original_url":    func(dst, src *models.Link) { dst.OriginalURL = src.OriginalURL },
+	"long_url_hash":   func(dst, src *models.Link) { dst.LongURLHash = src.LongURLHash },
+	"domain":          func(dst, src *models.Link) { dst.Domain = src.Domain },
+	"disabled":        func(dst, src *models.Link) { dst.Disabled = src.Disabled },
+	"health_status":   func(dst, src *models.Link) { dst.HealthStatus = src.HealthStatus },
+	"last_checked_at": func(dst, src *models.Link) { dst.LastCheckedAt = src.LastCheckedAt },
+}
+
+// DeleteLink soft-deletes a link by setting its DeletedAt field; its clicks are kept.
+// Parameters:
+//   - link: pointer to the Link model to delete
+//
+// Returns:
+//   - error: nil on success, or an error wrapping gorm.ErrRecordNotFound if the link does not exist
+func (r *MemoryLinkRepository) DeleteLink(link *models.Link) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	i := r.store.findLink(link.ID)
+	if i < 0 {
+		return fmt.Errorf("failed to delete link %s: %w", link.ShortCode, gorm.ErrRecordNotFound)
+	}
+	deletedAt := gorm.DeletedAt{Time: time.Now(), Valid: true}
+	r.store.links[i].DeletedAt = deletedAt
+	link.DeletedAt = deletedAt
+	return nil
+}
+
+// ListLinks retrieves one page of non-deleted links with the same filters, ordering and
+// keyset semantics as GormLinkRepository.ListLinks.
+// Parameters:
+//   - query: filters, sort order, page size and the cursor of the previous page
+//
+// Returns:
+//   - []LinkWithClicks: at most query.Limit links in the requested order
+//   - error: always nil
+func (r *MemoryLinkRepository) ListLinks(query LinkListQuery) ([]LinkWithClicks, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	filter := query.Filter
+	var links []LinkWithClicks
+	for _, link := range r.store.links {
+		if link.DeletedAt.Valid {
+			continue
+		}
+		if filter.Workspace != "" && link.Workspace != filter.Workspace {
+			continue
+		}
+		if filter.Domain != "" && link.Domain != filter.Domain && !strings.HasSuffix(link.Domain, "."+filter.Domain) {
+			continue
+		}
+		if filter.CreatedAfter != nil && link.CreatedAt.Before(*filter.CreatedAfter) {
+			continue
+		}
+		if filter.CreatedBefore != nil && !link.CreatedAt.Before(*filter.CreatedBefore) {
+			continue
+		}
+		if filter.HealthStatus != "" && link.HealthStatus != filter.HealthStatus {
+			continue
+		}
+		links = append(links, LinkWithClicks{Link: link, ClickCount: r.store.countClicks(link.ID)})
+	}
+
+	// compare orders two links by the sort key, then by ID: negative if a comes first in ascending order
+	compare := func(a, b LinkWithClicks) int {
+		if query.SortBy == SortByClicks {
+			if a.ClickCount != b.ClickCount {
+				return a.ClickCount - b.ClickCount
+			}
+		} else if !a.CreatedAt.Equal(b.CreatedAt) {
+			if a.CreatedAt.Before(b.CreatedAt) {
+				return -1
+			}
+			return 1
+		}
+		return int(a.ID) - int(b.ID)
+	}
+	if query.Descending {
+		ascending := compare
+		compare = func(a, b LinkWithClicks) int { return ascending(b, a) }
+	}
+
+	sort.Slice(links, func(i, j int) bool { return compare(links[i], links[j]) < 0 })
+
+	// Keyset pagination: skip everything up to and including the cursor position
+	if query.After != nil {
+		cursor := LinkWithClicks{Link: models.Link{ID: query.After.ID, CreatedAt: query.After.CreatedAt}, ClickCount: query.After.Clicks}
+		start := sort.Search(len(links), func(i int) bool { return compare(links[i], cursor) > 0 })
+		links = links[start:]
+	}
+
+	if query.Limit > 0 && len(links) > query.Limit {
+		links = links[:query.Limit]
+	}
+	return links, nil
+}
+
+// UpdateHealthStatus stores the result of a URL monitor check without touching the other fields.
+// Parameters:
+//   - linkID: the ID of the checked link
+//   - status: models.HealthUp or models.HealthDown
+//   - checkedAt: when the check was performed
+//
+// Returns:
+//   - error: always nil; unknown IDs are ignored like an UPDATE matching no row
+func (r *MemoryLinkRepository) UpdateHealthStatus(linkID uint, status string, checkedAt time.Time) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	if i := r.store.findLink(linkID); i >= 0 {
+		r.store.links[i].HealthStatus = status
+		r.store.links[i].LastCheckedAt = &checkedAt
+	}
+	return nil
+}
+
+// CountClicksByLinkID counts the clicks recorded in the shared store for a link.
+// Parameters:
+//   - linkID: the ID of the link to count clicks for
+//
+// Returns:
+//   - int: total number of clicks recorded for this link
+//   - error: always nil
+func (r *MemoryLinkRepository) CountClicksByLinkID(linkID uint) (int, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return r.store.countClicks(linkID), nil
+}
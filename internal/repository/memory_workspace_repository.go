@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// MemoryWorkspaceRepository is the thread-safe in-memory implementation of the WorkspaceRepository interface.
+// Used by the server's ephemeral mode; the store starts with the default workspace only.
+type MemoryWorkspaceRepository struct {
+	store *MemoryStore // Shared in-memory data
+}
+
+// NewMemoryWorkspaceRepository creates and returns a new instance of MemoryWorkspaceRepository.
+// Parameters:
+//   - store: in-memory store holding the workspaces
+//
+// Returns:
+//   - *MemoryWorkspaceRepository: configured repository instance ready for use
+func NewMemoryWorkspaceRepository(store *MemoryStore) *MemoryWorkspaceRepository {
+	return &MemoryWorkspaceRepository{store: store}
+}
+
+// CreateWorkspace stores a new workspace, assigning its ID and creation time like the database would.
+// Returns an error if the slug is already used.
+func (r *MemoryWorkspaceRepository) CreateWorkspace(workspace *models.Workspace) error {
+	r.store.mu.Lock()
+	defer r.store.mu.Unlock()
+
+	for i := range r.store.workspaces {
+		if r.store.workspaces[i].Slug == workspace.Slug {
+			return fmt.Errorf("failed to create workspace %s: slug already exists", workspace.Slug)
+		}
+	}
+
+	workspace.ID = r.store.nextWorkspaceID
+	r.store.nextWorkspaceID++
+	if workspace.CreatedAt.IsZero() {
+		workspace.CreatedAt = time.Now()
+	}
+	r.store.workspaces = append(r.store.workspaces, *workspace)
+	return nil
+}
+
+// GetWorkspaceBySlug retrieves a workspace by its slug.
+// Returns gorm.ErrRecordNotFound if no workspace has this slug.
+func (r *MemoryWorkspaceRepository) GetWorkspaceBySlug(slug string) (*models.Workspace, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	for i := range r.store.workspaces {
+		if r.store.workspaces[i].Slug == slug {
+			workspace := r.store.workspaces[i]
+			return &workspace, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ListWorkspaces returns a copy of every workspace ordered by ID.
+func (r *MemoryWorkspaceRepository) ListWorkspaces() ([]models.Workspace, error) {
+	r.store.mu.RLock()
+	defer r.store.mu.RUnlock()
+
+	return append([]models.Workspace(nil), r.store.workspaces...), nil
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+	"gorm.io/gorm"
+)
+
+// WorkspaceRepository is an interface that defines data access methods for workspaces.
+type WorkspaceRepository interface {
+	// CreateWorkspace inserts a new workspace.
+	CreateWorkspace(workspace *models.Workspace) error
+
+	// GetWorkspaceBySlug retrieves a workspace by its slug.
+	// Used to resolve the short code namespace of a workspace when creating or looking up links.
+	GetWorkspaceBySlug(slug string) (*models.Workspace, error)
+
+	// ListWorkspaces returns every workspace, oldest first.
+	ListWorkspaces() ([]models.Workspace, error)
+}
+
+// GormWorkspaceRepository is the GORM-based implementation of the WorkspaceRepository interface.
+type GormWorkspaceRepository struct {
+	db *gorm.DB // GORM database connection instance
+}
+
+// NewWorkspaceRepository creates and returns a new instance of GormWorkspaceRepository.
+// Parameters:
+//   - db: GORM database connection to use for all operations
+//
+// Returns:
+//   - *GormWorkspaceRepository: configured repository instance ready for use
+func NewWorkspaceRepository(db *gorm.DB) *GormWorkspaceRepository {
+	return &GormWorkspaceRepository{db: db}
+}
+
+// CreateWorkspace inserts a new workspace record into the database.
+// Parameters:
+//   - workspace: the workspace to insert; its ID is filled in on success
+//
+// Returns:
+//   - error: nil on success, or database error if insertion fails (e.g., duplicate slug)
+func (r *GormWorkspaceRepository) CreateWorkspace(workspace *models.Workspace) error {
+	if err := r.db.Create(workspace).Error; err != nil {
+		return fmt.Errorf("failed to create workspace %s: %w", workspace.Slug, err)
+	}
+	return nil
+}
+
+// GetWorkspaceBySlug retrieves a workspace by its slug.
+// Find is used instead of First so that checking a free slug does not log a "record not found" error.
+// Parameters:
+//   - slug: the unique slug of the workspace
+//
+// Returns:
+//   - *models.Workspace: the found workspace
+//   - error: gorm.ErrRecordNotFound if no workspace has this slug, or database error
+func (r *GormWorkspaceRepository) GetWorkspaceBySlug(slug string) (*models.Workspace, error) {
+	var workspace models.Workspace
+	result := r.db.Where("slug = ?", slug).Limit(1).Find(&workspace)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &workspace, nil
+}
+
+// ListWorkspaces returns every workspace ordered by ID.
+// Returns:
+//   - []models.Workspace: all workspaces
+//   - error: nil on success, or database error if query fails
+func (r *GormWorkspaceRepository) ListWorkspaces() ([]models.Workspace, error) {
+	var workspaces []models.Workspace
+	if err := r.db.Order("id").Find(&workspaces).Error; err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	return workspaces, nil
+}
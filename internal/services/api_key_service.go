@@ -0,0 +1,195 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	customerrors "github.com/axellelanca/urlshortener/internal/errors"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"gorm.io/gorm"
+)
+
+// apiKeyPrefix starts every plain API key so leaked keys are easy to recognize (e.g. by secret scanners).
+const apiKeyPrefix = "usk_"
+
+// apiKeyRandomBytes is the number of random bytes in an API key (256 bits).
+// With that much entropy a fast unsalted SHA-256 hash is enough to protect stored keys.
+const apiKeyRandomBytes = 32
+
+// apiKeyDisplayLength is the number of leading characters of a plain key stored in clear for listings.
+const apiKeyDisplayLength = 12
+
+// apiKeyTouchInterval bounds how often the last usage date of a key is written,
+// so a busy client does not cause one database write per request.
+const apiKeyTouchInterval = time.Minute
+
+// validScopes lists the scopes accepted when creating an API key.
+var validScopes = map[string]bool{
+	models.ScopeCreate:    true,
+	models.ScopeReadStats: true,
+	models.ScopeAdmin:     true,
+}
+
+// APIKeyService provides business logic methods for managing and checking API keys.
+type APIKeyService struct {
+	apiKeyRepo    repository.APIKeyRepository    // Repository interface for API key data operations
+	workspaceRepo repository.WorkspaceRepository // Checks that a key is bound to an existing workspace
+}
+
+// NewAPIKeyService creates and returns a new instance of APIKeyService.
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository, workspaceRepo repository.WorkspaceRepository) *APIKeyService {
+	return &APIKeyService{apiKeyRepo: apiKeyRepo, workspaceRepo: workspaceRepo}
+}
+
+// CreateAPIKey generates a new random API key bound to a workspace and stores its hash.
+// Parameters:
+//   - name: label identifying the key owner
+//   - workspace: slug of the workspace the key acts on; models.DefaultWorkspace when empty
+//   - scopes: granted scopes (models.ScopeCreate, models.ScopeReadStats, models.ScopeAdmin)
+//
+// Returns:
+//   - string: the plain key, to be shown once to the user; it cannot be recovered afterwards
+//   - *models.APIKey: the stored key
+//   - error: ErrInvalidScope if a scope is unknown or missing, ErrWorkspaceNotFound, or a database error
+func (s *APIKeyService) CreateAPIKey(name, workspace string, scopes []string) (string, *models.APIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", nil, fmt.Errorf("API key name cannot be empty")
+	}
+	normalized, err := normalizeScopes(scopes)
+	if err != nil {
+		return "", nil, err
+	}
+	if workspace == "" {
+		workspace = models.DefaultWorkspace
+	}
+	if _, err := s.workspaceRepo.GetWorkspaceBySlug(workspace); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil, fmt.Errorf("%w: '%s'", customerrors.ErrWorkspaceNotFound, workspace)
+		}
+		return "", nil, fmt.Errorf("failed to retrieve workspace %s: %w", workspace, err)
+	}
+
+	random := make([]byte, apiKeyRandomBytes)
+	if _, err := rand.Read(random); err != nil {
+		return "", nil, fmt.Errorf("failed to generate API key: %w", err)
+	}
+	plainKey := apiKeyPrefix + hex.EncodeToString(random)
+
+	key := &models.APIKey{
+		Name:      name,
+		Prefix:    plainKey[:apiKeyDisplayLength],
+		KeyHash:   hashAPIKey(plainKey),
+		Workspace: workspace,
+		Scopes:    strings.Join(normalized, ","),
+	}
+	if err := s.apiKeyRepo.CreateAPIKey(key); err != nil {
+		return "", nil, fmt.Errorf("failed to create API key: %w", err)
+	}
+	return plainKey, key, nil
+}
+
+// Authenticate resolves a plain API key to its stored record.
+// The last usage date of the key is refreshed at most once per apiKeyTouchInterval.
+// Parameters:
+//   - plainKey: the key sent by the client
+//
+// Returns:
+//   - *models.APIKey: the matching active key
+//   - error: ErrInvalidAPIKey if the key is empty, unknown or revoked, or a database error
+func (s *APIKeyService) Authenticate(plainKey string) (*models.APIKey, error) {
+	if plainKey == "" {
+		return nil, customerrors.ErrInvalidAPIKey
+	}
+
+	key, err := s.apiKeyRepo.GetAPIKeyByHash(hashAPIKey(plainKey))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customerrors.ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("failed to look up API key: %w", err)
+	}
+	if key.Revoked() {
+		return nil, customerrors.ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		// A failed usage update must not reject an otherwise valid request
+		if err := s.apiKeyRepo.TouchAPIKey(key.ID, now); err == nil {
+			key.LastUsedAt = &now
+		}
+	}
+	return key, nil
+}
+
+// ListAPIKeys returns every API key, revoked ones included.
+func (s *APIKeyService) ListAPIKeys() ([]models.APIKey, error) {
+	keys, err := s.apiKeyRepo.ListAPIKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys: %w", err)
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey revokes a key so it can no longer authenticate requests.
+// Revoking an already revoked key keeps its original revocation date.
+// Parameters:
+//   - id: the database ID of the key
+//
+// Returns:
+//   - *models.APIKey: the revoked key
+//   - error: ErrAPIKeyNotFound if no key has this ID, or a database error
+func (s *APIKeyService) RevokeAPIKey(id uint) (*models.APIKey, error) {
+	key, err := s.apiKeyRepo.GetAPIKeyByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customerrors.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to retrieve API key %d: %w", id, err)
+	}
+	if key.Revoked() {
+		return key, nil
+	}
+
+	now := time.Now()
+	if err := s.apiKeyRepo.RevokeAPIKey(id, now); err != nil {
+		return nil, err
+	}
+	key.RevokedAt = &now
+	return key, nil
+}
+
+// normalizeScopes trims, lowercases, deduplicates and validates a list of scopes.
+func normalizeScopes(scopes []string) ([]string, error) {
+	var normalized []string
+	seen := make(map[string]bool)
+	for _, scope := range scopes {
+		scope = strings.ToLower(strings.TrimSpace(scope))
+		if scope == "" || seen[scope] {
+			continue
+		}
+		if !validScopes[scope] {
+			return nil, fmt.Errorf("%w: '%s' (expected %s, %s or %s)", customerrors.ErrInvalidScope,
+				scope, models.ScopeCreate, models.ScopeReadStats, models.ScopeAdmin)
+		}
+		seen[scope] = true
+		normalized = append(normalized, scope)
+	}
+	if len(normalized) == 0 {
+		return nil, fmt.Errorf("%w: at least one scope is required", customerrors.ErrInvalidScope)
+	}
+	return normalized, nil
+}
+
+// hashAPIKey returns the hex-encoded SHA-256 hash under which a plain key is stored.
+func hashAPIKey(plainKey string) string {
+	sum := sha256.Sum256([]byte(plainKey))
+	return hex.EncodeToString(sum[:])
+}
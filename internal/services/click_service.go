@@ -1,12 +1,38 @@
 package services
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
+	customerrors "github.com/axellelanca/urlshortener/internal/errors"
 	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository"
 )
 
+// maxTimeSeriesPoints bounds the number of buckets a single time-series query may return.
+// It keeps responses small (e.g. ~41 days of hourly data, ~19 years of weekly data).
+const maxTimeSeriesPoints = 1000
+
+// topReferrersLimit is the number of referrers reported in link statistics.
+const topReferrersLimit = 10
+
+// defaultTimeSeriesPoints is the number of buckets returned when no start date is given.
+var defaultTimeSeriesPoints = map[string]int{
+	repository.GranularityHour: 24,
+	repository.GranularityDay:  30,
+	repository.GranularityWeek: 12,
+}
+
+// TimeSeriesPoint is the number of clicks in one bucket of a time series.
+type TimeSeriesPoint struct {
+	Start time.Time // Beginning of the bucket, in UTC
+	Count int       // Number of clicks in the bucket
+}
+
 // ClickService provides business logic methods for managing click events.
 // This service handles the recording and querying of user interactions with short links.
 type ClickService struct {
@@ -51,3 +77,171 @@ func (s *ClickService) GetClicksCountByLinkID(linkID uint) (int, error) {
 	}
 	return count, nil
 }
+
+// GetClickTimeSeries returns the clicks of a link bucketed by hour, day or week.
+// Every bucket between from and to is present in the result, with a zero count when no click was recorded,
+// so the series can be plotted directly.
+// Parameters:
+//   - linkID: the database ID of the link
+//   - granularity: repository.GranularityHour, GranularityDay or GranularityWeek
+//   - from: start of the range, rounded down to the start of its bucket; zero means
+//     24 buckets (hour), 30 buckets (day) or 12 buckets (week) before 'to'
+//   - to: end of the range (exclusive); zero means now
+//
+// Returns:
+//   - []TimeSeriesPoint: one point per bucket, in chronological order
+//   - error: ErrInvalidTimeRange for a bad granularity or range, or database errors
+func (s *ClickService) GetClickTimeSeries(linkID uint, granularity string, from, to time.Time) ([]TimeSeriesPoint, error) {
+	step, ok := granularityStep(granularity)
+	if !ok {
+		return nil, fmt.Errorf("%w: granularity must be '%s', '%s' or '%s'", customerrors.ErrInvalidTimeRange,
+			repository.GranularityHour, repository.GranularityDay, repository.GranularityWeek)
+	}
+
+	// Default to a window ending now that is easy to read at this granularity
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = to.Add(-time.Duration(defaultTimeSeriesPoints[granularity]-1) * step)
+	}
+	if !from.Before(to) {
+		return nil, fmt.Errorf("%w: 'from' must be before 'to'", customerrors.ErrInvalidTimeRange)
+	}
+
+	start := truncateToBucket(from.UTC(), granularity)
+	if points := int(to.Sub(start)/step) + 1; points > maxTimeSeriesPoints {
+		return nil, fmt.Errorf("%w: range covers %d %s buckets, maximum is %d",
+			customerrors.ErrInvalidTimeRange, points, granularity, maxTimeSeriesPoints)
+	}
+
+	buckets, err := s.clickRepo.CountClicksByInterval(linkID, granularity, start, to)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[time.Time]int, len(buckets))
+	for _, bucket := range buckets {
+		counts[bucket.Start] = bucket.Count
+	}
+
+	// Walk the range bucket by bucket so empty buckets show up as zeros
+	var series []TimeSeriesPoint
+	for t := start; t.Before(to); t = t.Add(step) {
+		series = append(series, TimeSeriesPoint{Start: t, Count: counts[t]})
+	}
+	return series, nil
+}
+
+// granularityStep returns the duration of one bucket for the given granularity.
+func granularityStep(granularity string) (time.Duration, bool) {
+	switch granularity {
+	case repository.GranularityHour:
+		return time.Hour, true
+	case repository.GranularityDay:
+		return 24 * time.Hour, true
+	case repository.GranularityWeek:
+		return 7 * 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+// truncateToBucket rounds a UTC time down to the start of its bucket.
+// Weeks start on Monday, matching the database-side bucketing.
+func truncateToBucket(t time.Time, granularity string) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch granularity {
+	case repository.GranularityHour:
+		return t.Truncate(time.Hour)
+	case repository.GranularityWeek:
+		// time.Weekday counts from Sunday (0); shift so Monday is 0
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	}
+	return day
+}
+
+// GetCountryBreakdown retrieves the number of clicks per country for a link.
+// Parameters:
+//   - linkID: the database ID of the link
+//
+// Returns:
+//   - []repository.GroupCount: clicks per ISO country code, most frequent first;
+//     clicks that could not be geolocated are reported under "unknown"
+//   - error: any error that occurred during retrieval
+func (s *ClickService) GetCountryBreakdown(linkID uint) ([]repository.GroupCount, error) {
+	counts, err := s.clickRepo.CountClicksByCountry(linkID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range counts {
+		if counts[i].Value == "" {
+			counts[i].Value = "unknown"
+		}
+	}
+	return counts, nil
+}
+
+// GetTopReferrers retrieves the hosts that sent the most clicks to a link.
+// Parameters:
+//   - linkID: the database ID of the link
+//
+// Returns:
+//   - []repository.GroupCount: up to 10 referrer hosts, most frequent first;
+//     clicks without a Referer header are reported under "direct"
+//   - error: any error that occurred during retrieval
+func (s *ClickService) GetTopReferrers(linkID uint) ([]repository.GroupCount, error) {
+	counts, err := s.clickRepo.CountClicksByReferrer(linkID, topReferrersLimit)
+	if err != nil {
+		return nil, err
+	}
+	for i := range counts {
+		if counts[i].Value == "" {
+			counts[i].Value = "direct"
+		}
+	}
+	return counts, nil
+}
+
+// NormalizeReferrer reduces a raw Referer header to its host so clicks can be grouped by channel.
+// The host is lowercased and a leading "www." is dropped, so "https://www.Twitter.com/x/status/1"
+// becomes "twitter.com". Empty or unparsable headers yield an empty string (direct traffic).
+func NormalizeReferrer(referer string) string {
+	referer = strings.TrimSpace(referer)
+	if referer == "" {
+		return ""
+	}
+	parsed, err := url.Parse(referer)
+	if err != nil || parsed.Hostname() == "" {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(parsed.Hostname()), "www.")
+}
+
+// GetUniqueVisitors retrieves the number of unique visitors of a link.
+// A visitor is one IP + user agent pair on one UTC day, so the same person coming back
+// on another day counts again, while refreshing the page ten times in a day counts once.
+// Parameters:
+//   - linkID: the database ID of the link
+//
+// Returns:
+//   - int: the number of unique visitor-days
+//   - error: any error that occurred during counting
+func (s *ClickService) GetUniqueVisitors(linkID uint) (int, error) {
+	return s.clickRepo.CountUniqueVisitorsByLinkID(linkID)
+}
+
+// VisitorHash derives an anonymous visitor identifier from the click's IP address and user agent.
+// The UTC day is part of the hash so identifiers cannot be linked across days, and the secret salt
+// prevents recovering the IP by brute-forcing the (small) IPv4 address space.
+// Parameters:
+//   - salt: server-side secret (analytics.visitor_salt)
+//   - ip: client IP address
+//   - userAgent: raw User-Agent header
+//   - timestamp: time of the click; only its UTC date is used
+//
+// Returns:
+//   - string: 32 hexadecimal characters (128 bits of the SHA-256 digest)
+func VisitorHash(salt, ip, userAgent string, timestamp time.Time) string {
+	digest := sha256.Sum256([]byte(salt + "|" + timestamp.UTC().Format("2006-01-02") + "|" + ip + "|" + userAgent))
+	return hex.EncodeToString(digest[:16])
+}
@@ -0,0 +1,317 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	customerrors "github.com/axellelanca/urlshortener/internal/errors"
+)
+
+// dnsLookupTimeout bounds the DNS resolution done when DestinationPolicyOptions.ResolveHosts is set.
+const dnsLookupTimeout = 2 * time.Second
+
+// nonPublicNetworks lists the ranges rejected on top of those recognized by the net.IP helpers
+// (loopback, RFC 1918/4193 private, link-local, unspecified).
+var nonPublicNetworks = mustParseCIDRs(
+	"0.0.0.0/8",     // "This network"; 0.x.x.x reaches the local host on many systems
+	"100.64.0.0/10", // Carrier-grade NAT shared address space
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // Benchmarking networks
+)
+
+// DestinationPolicyOptions configures a DestinationPolicy.
+type DestinationPolicyOptions struct {
+	AllowedSchemes       []string      // URL schemes accepted as destinations (e.g. "http", "https")
+	BlocklistPath        string        // File of domains that are always rejected; empty disables the blocklist
+	AllowlistPath        string        // File of the only domains accepted; empty (or an empty file) accepts every domain
+	ReloadInterval       time.Duration // Minimum delay between two checks of the list files for changes
+	BlockPrivateNetworks bool          // Reject localhost and loopback, private and link-local addresses
+	ResolveHosts         bool          // Also resolve domain names and reject those pointing to such addresses
+}
+
+// DestinationPolicy decides which URLs links may redirect to.
+// It protects visitors (phishing domains, dangerous schemes) and the server itself: the URL monitor
+// fetches every destination, so a link to an internal address would let anyone probe the private network.
+// The domain lists are plain text files, one domain per line ('#' starts a comment); a domain also
+// matches its subdomains. The files are re-read when they change, without restarting the server.
+// DestinationPolicy is safe for concurrent use.
+type DestinationPolicy struct {
+	allowedSchemes       map[string]bool // Lowercased accepted schemes
+	blocklist            *domainListFile // Always-rejected domains, nil if not configured
+	allowlist            *domainListFile // Only-accepted domains, nil if not configured
+	blockPrivateNetworks bool
+	resolveHosts         bool
+}
+
+// NewDestinationPolicy creates a destination policy and loads its domain lists.
+// A list file that does not exist yet is treated as empty and picked up once it is created.
+// Parameters:
+//   - opts: accepted schemes, list files and private network handling
+//
+// Returns:
+//   - *DestinationPolicy: the policy, ready for use
+//   - error: if no scheme is allowed or a list file exists but cannot be read
+func NewDestinationPolicy(opts DestinationPolicyOptions) (*DestinationPolicy, error) {
+	policy := &DestinationPolicy{
+		allowedSchemes:       make(map[string]bool),
+		blockPrivateNetworks: opts.BlockPrivateNetworks,
+		resolveHosts:         opts.ResolveHosts,
+	}
+	for _, scheme := range opts.AllowedSchemes {
+		if scheme = strings.ToLower(strings.TrimSpace(scheme)); scheme != "" {
+			policy.allowedSchemes[scheme] = true
+		}
+	}
+	if len(policy.allowedSchemes) == 0 {
+		return nil, fmt.Errorf("destination policy: at least one URL scheme must be allowed")
+	}
+
+	var err error
+	if policy.blocklist, err = newDomainListFile(opts.BlocklistPath, opts.ReloadInterval); err != nil {
+		return nil, err
+	}
+	if policy.allowlist, err = newDomainListFile(opts.AllowlistPath, opts.ReloadInterval); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Check verifies that a URL is an acceptable link destination.
+// Parameters:
+//   - ctx: bounds the DNS resolution when ResolveHosts is enabled
+//   - rawURL: the destination to check
+//
+// Returns:
+//   - error: nil if the URL is accepted, ErrInvalidURL if it has no scheme or host,
+//     or an ErrDestinationBlocked explaining why it is rejected
+func (p *DestinationPolicy) Check(ctx context.Context, rawURL string) error {
+	invalid := fmt.Errorf("%w: %s (an absolute URL with a host is required)", customerrors.ErrInvalidURL, rawURL)
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Scheme == "" {
+		return invalid
+	}
+	blocked := func(reason string) error {
+		return customerrors.ErrDestinationBlocked{URL: rawURL, Reason: reason}
+	}
+
+	// Checked before the host so that "javascript:..." or "data:..." are reported as blocked schemes
+	if scheme := strings.ToLower(parsed.Scheme); !p.allowedSchemes[scheme] {
+		return blocked(fmt.Sprintf("scheme '%s' is not allowed", scheme))
+	}
+	if parsed.Hostname() == "" {
+		return invalid
+	}
+	// "https://trusted.com@evil.com" looks like trusted.com but leads to evil.com
+	if parsed.User != nil {
+		return blocked("URLs with embedded credentials are not allowed")
+	}
+
+	host := strings.TrimSuffix(strings.ToLower(parsed.Hostname()), ".")
+	if p.allowlist != nil {
+		if allowed := p.allowlist.domains(); len(allowed) > 0 && !matchesDomain(allowed, host) {
+			return blocked(fmt.Sprintf("domain '%s' is not in the allowlist", host))
+		}
+	}
+	if p.blocklist != nil && matchesDomain(p.blocklist.domains(), host) {
+		return blocked(fmt.Sprintf("domain '%s' is blocklisted", host))
+	}
+
+	if p.blockPrivateNetworks {
+		if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return blocked("localhost is not allowed")
+		}
+		if ip := parseHostIP(host); ip != nil {
+			if isNonPublicIP(ip) {
+				return blocked(fmt.Sprintf("address %s is private, loopback or reserved", ip))
+			}
+		} else if p.resolveHosts {
+			lookupCtx, cancel := context.WithTimeout(ctx, dnsLookupTimeout)
+			defer cancel()
+			// A name that cannot be resolved right now is not rejected: it cannot reach the private network either
+			addrs, _ := net.DefaultResolver.LookupIPAddr(lookupCtx, host)
+			for _, addr := range addrs {
+				if isNonPublicIP(addr.IP) {
+					return blocked(fmt.Sprintf("domain '%s' resolves to the private or reserved address %s", host, addr.IP))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// matchesDomain reports whether host or one of its parent domains is in the set.
+func matchesDomain(domains map[string]bool, host string) bool {
+	if len(domains) == 0 {
+		return false
+	}
+	for {
+		if domains[host] {
+			return true
+		}
+		dot := strings.IndexByte(host, '.')
+		if dot < 0 {
+			return false
+		}
+		host = host[dot+1:]
+	}
+}
+
+// parseHostIP returns the IP address written in a URL host, or nil if the host is a domain name.
+// Besides canonical IPv4 and IPv6 addresses it recognizes the legacy IPv4 forms that browsers
+// and HTTP clients still accept (e.g. "2130706433", "0x7f.1", "0177.0.0.1" all mean 127.0.0.1).
+func parseHostIP(host string) net.IP {
+	if ip := net.ParseIP(host); ip != nil {
+		return ip
+	}
+	parts := strings.Split(host, ".")
+	if len(parts) > 4 {
+		return nil
+	}
+	values := make([]uint64, len(parts))
+	for i, part := range parts {
+		value, err := strconv.ParseUint(part, 0, 32) // base 0: "0x" is hexadecimal, a leading "0" is octal
+		if err != nil {
+			return nil
+		}
+		values[i] = value
+	}
+	// Like inet_aton, the last part fills all the remaining bytes of the address
+	last := len(values) - 1
+	if values[last] >= 1<<(8*(4-last)) {
+		return nil
+	}
+	address := values[last]
+	for i := 0; i < last; i++ {
+		if values[i] > 0xff {
+			return nil
+		}
+		address |= values[i] << (8 * (3 - i))
+	}
+	return net.IPv4(byte(address>>24), byte(address>>16), byte(address>>8), byte(address))
+}
+
+// isNonPublicIP reports whether an address must not be used as a link destination.
+func isNonPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return true
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// mustParseCIDRs parses constant CIDR blocks, panicking on a typo.
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// domainListFile is a domain list read from a text file and re-read when the file changes.
+// The modification time is checked at most once per reload interval, on demand, so no
+// background goroutine is needed and the CLI behaves like the server.
+type domainListFile struct {
+	path     string        // Location of the file
+	interval time.Duration // Minimum delay between two modification checks
+
+	mu        sync.Mutex      // Guards every field below
+	set       map[string]bool // Current domains
+	modTime   time.Time       // Modification time of the loaded version; zero if the file was missing
+	checkedAt time.Time       // Last modification check
+}
+
+// newDomainListFile loads a domain list file; it returns nil when path is empty.
+func newDomainListFile(path string, interval time.Duration) (*domainListFile, error) {
+	if path == "" {
+		return nil, nil
+	}
+	list := &domainListFile{path: path, interval: interval, checkedAt: time.Now()}
+	if err := list.reload(); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+// domains returns the current domain set, reloading the file first if it changed.
+// A failed reload keeps the previous list so a half-written file cannot disable the policy.
+func (l *domainListFile) domains() map[string]bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now := time.Now(); now.Sub(l.checkedAt) >= l.interval {
+		l.checkedAt = now
+		if err := l.reload(); err != nil {
+			slog.Warn("Failed to reload destination list, keeping the previous version", "path", l.path, "error", err)
+		}
+	}
+	return l.set
+}
+
+// reload reads the file if its modification time differs from the loaded version.
+// A missing file is an empty list. Callers must hold the lock (or own the list exclusively).
+func (l *domainListFile) reload() error {
+	info, err := os.Stat(l.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		if l.set == nil || !l.modTime.IsZero() {
+			slog.Warn("Destination list file not found, treating it as empty", "path", l.path)
+		}
+		l.set, l.modTime = map[string]bool{}, time.Time{}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read destination list %s: %w", l.path, err)
+	}
+	if l.set != nil && info.ModTime().Equal(l.modTime) {
+		return nil
+	}
+
+	file, err := os.Open(l.path)
+	if err != nil {
+		return fmt.Errorf("failed to read destination list %s: %w", l.path, err)
+	}
+	defer file.Close()
+
+	set := make(map[string]bool)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		// "*.example.com" and ".example.com" are accepted as spellings of "example.com" (subdomains always match)
+		domain := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(strings.TrimSpace(line)), "*"), ".")
+		if domain = strings.TrimSuffix(domain, "."); domain != "" {
+			set[domain] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read destination list %s: %w", l.path, err)
+	}
+
+	// The first load happens on every CLI invocation, so only reloads are worth reporting by default
+	if l.set == nil {
+		slog.Debug("Destination list loaded", "path", l.path, "domains", len(set))
+	} else {
+		slog.Info("Destination list reloaded", "path", l.path, "domains", len(set))
+	}
+	l.set, l.modTime = set, info.ModTime()
+	return nil
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	customerrors "github.com/axellelanca/urlshortener/internal/errors"
+)
+
+func TestParseHostIP(t *testing.T) {
+	tests := []struct {
+		host string
+		want string // "" when the host is not an IP address
+	}{
+		{"127.0.0.1", "127.0.0.1"},
+		{"::1", "::1"},
+		{"2130706433", "127.0.0.1"},
+		{"0x7f000001", "127.0.0.1"},
+		{"0x7f.1", "127.0.0.1"},
+		{"0177.0.0.1", "127.0.0.1"},
+		{"127.1", "127.0.0.1"},
+		{"10.0.258", "10.0.1.2"},
+		{"192.168.0x1.1", "192.168.1.1"},
+		{"example.com", ""},
+		{"1.2.3.4.5", ""},
+		{"256.0.0.1", ""},
+		{"1.2.3.256", ""},
+		{"1.16777216", ""},
+		{"4294967296", ""},
+		{"08.0.0.1", ""}, // "08" is an invalid octal number
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got := parseHostIP(tt.host)
+		if tt.want == "" {
+			if got != nil {
+				t.Errorf("parseHostIP(%q) = %s, want nil", tt.host, got)
+			}
+			continue
+		}
+		if !got.Equal(net.ParseIP(tt.want)) {
+			t.Errorf("parseHostIP(%q) = %v, want %s", tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestIsNonPublicIP(t *testing.T) {
+	tests := []struct {
+		ip        string
+		nonPublic bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true}, // Cloud metadata endpoint
+		{"0.0.0.0", true},
+		{"0.1.2.3", true},
+		{"100.64.0.1", true},
+		{"192.0.0.8", true},
+		{"198.18.0.1", true},
+		{"::1", true},
+		{"::", true},
+		{"fc00::1", true},
+		{"fe80::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"::ffff:10.0.0.1", true},
+		{"8.8.8.8", false},
+		{"100.128.0.1", false},
+		{"172.32.0.1", false},
+		{"2606:4700:4700::1111", false},
+	}
+	for _, tt := range tests {
+		if got := isNonPublicIP(net.ParseIP(tt.ip)); got != tt.nonPublic {
+			t.Errorf("isNonPublicIP(%s) = %v, want %v", tt.ip, got, tt.nonPublic)
+		}
+	}
+}
+
+func TestDestinationPolicyCheck(t *testing.T) {
+	dir := t.TempDir()
+	blocklist := filepath.Join(dir, "blocklist.txt")
+	if err := os.WriteFile(blocklist, []byte("# phishing\nevil.example\n\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := NewDestinationPolicy(DestinationPolicyOptions{
+		AllowedSchemes:       []string{"http", "HTTPS"},
+		BlocklistPath:        blocklist,
+		AllowlistPath:        filepath.Join(dir, "missing.txt"), // Treated as empty: every domain is allowed
+		BlockPrivateNetworks: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		url     string
+		blocked bool
+		invalid bool
+	}{
+		{url: "https://example.com/page"},
+		{url: "HTTP://Example.com"},
+		{url: "https://8.8.8.8/"},
+		{url: "javascript:alert(1)", blocked: true},
+		{url: "ftp://example.com/file", blocked: true},
+		{url: "https://trusted.com@evil.com/", blocked: true},
+		{url: "https://evil.example/", blocked: true},
+		{url: "https://login.EVIL.example./", blocked: true},
+		{url: "https://notevil.example/"},
+		{url: "http://localhost:8080/", blocked: true},
+		{url: "http://api.localhost/", blocked: true},
+		{url: "http://127.0.0.1/", blocked: true},
+		{url: "http://2130706433/", blocked: true},
+		{url: "http://[::1]/", blocked: true},
+		{url: "http://169.254.169.254/latest/meta-data", blocked: true},
+		{url: "example.com", invalid: true},
+		{url: "https:///path", invalid: true},
+	}
+	for _, tt := range tests {
+		err := policy.Check(context.Background(), tt.url)
+		var blockedErr customerrors.ErrDestinationBlocked
+		switch {
+		case tt.blocked && !errors.As(err, &blockedErr):
+			t.Errorf("%s: expected ErrDestinationBlocked, got %v", tt.url, err)
+		case tt.invalid && !errors.Is(err, customerrors.ErrInvalidURL):
+			t.Errorf("%s: expected ErrInvalidURL, got %v", tt.url, err)
+		case !tt.blocked && !tt.invalid && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.url, err)
+		}
+	}
+}
+
+func TestDestinationPolicyAllowlist(t *testing.T) {
+	allowlist := filepath.Join(t.TempDir(), "allowlist.txt")
+	if err := os.WriteFile(allowlist, []byte("example.com\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	policy, err := NewDestinationPolicy(DestinationPolicyOptions{AllowedSchemes: []string{"https"}, AllowlistPath: allowlist})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := policy.Check(context.Background(), "https://docs.example.com/"); err != nil {
+		t.Errorf("subdomain of an allowlisted domain rejected: %v", err)
+	}
+	if err := policy.Check(context.Background(), "https://example.org/"); err == nil {
+		t.Error("domain outside the allowlist accepted")
+	}
+	if err := policy.Check(context.Background(), "https://example.com.evil/"); err == nil {
+		t.Error("suffix match on a different domain accepted")
+	}
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// GeoLocation is the geographic origin of an IP address.
+// Fields are empty when the address is unknown to the database (e.g. private or loopback IPs).
+type GeoLocation struct {
+	Country string // ISO 3166-1 alpha-2 country code (e.g. "FR")
+	Region  string // Name of the first-level subdivision (e.g. "Île-de-France")
+}
+
+// GeoLocator resolves IP addresses to geographic locations.
+// Implementations must be safe for concurrent use since every click worker shares the same instance.
+type GeoLocator interface {
+	Lookup(ip string) (GeoLocation, error)
+	Close() error
+}
+
+// MaxMindGeoLocator is a GeoLocator backed by a local MaxMind-format database file
+// (GeoLite2/GeoIP2 Country or City, or any compatible .mmdb file).
+// Lookups are served entirely from the memory-mapped file, so no network access is needed.
+type MaxMindGeoLocator struct {
+	reader *maxminddb.Reader // Thread-safe reader over the memory-mapped database
+}
+
+// mmdbRecord lists the fields read from each database entry.
+// Country databases only contain 'country'; City databases also contain 'subdivisions'.
+type mmdbRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	Subdivisions []struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"subdivisions"`
+}
+
+// NewMaxMindGeoLocator opens a MaxMind-format database file.
+// Parameters:
+//   - path: location of the .mmdb file on disk
+//
+// Returns:
+//   - *MaxMindGeoLocator: ready-to-use locator; call Close when done
+//   - error: if the file is missing or is not a valid MaxMind database
+func NewMaxMindGeoLocator(path string) (*MaxMindGeoLocator, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open GeoIP database %s: %w", path, err)
+	}
+	return &MaxMindGeoLocator{reader: reader}, nil
+}
+
+// Lookup resolves an IP address to its country and region.
+// An address missing from the database is not an error: an empty GeoLocation is returned.
+// Parameters:
+//   - ip: textual IPv4 or IPv6 address
+//
+// Returns:
+//   - GeoLocation: the resolved location, possibly empty
+//   - error: if the address cannot be parsed or the database is corrupted
+func (g *MaxMindGeoLocator) Lookup(ip string) (GeoLocation, error) {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return GeoLocation{}, fmt.Errorf("invalid IP address '%s'", ip)
+	}
+
+	var record mmdbRecord
+	if err := g.reader.Lookup(parsed, &record); err != nil {
+		return GeoLocation{}, fmt.Errorf("failed to look up IP %s: %w", ip, err)
+	}
+
+	location := GeoLocation{Country: record.Country.ISOCode}
+	if len(record.Subdivisions) > 0 {
+		location.Region = record.Subdivisions[0].Names["en"]
+	}
+	return location, nil
+}
+
+// Close releases the memory-mapped database file.
+func (g *MaxMindGeoLocator) Close() error {
+	return g.reader.Close()
+}
@@ -1,16 +1,23 @@
 package services
 
 import (
+	"context"
 	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
-	"log"
 	"math/big"
+	"net/http"
+	"strings"
 	"time"
 
 	customerrors "github.com/axellelanca/urlshortener/internal/errors"
+	"github.com/axellelanca/urlshortener/internal/logging"
+	"github.com/axellelanca/urlshortener/internal/metrics"
 	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/urlnorm"
 	"gorm.io/gorm"
 )
 
@@ -19,21 +26,105 @@ import (
 // This gives us 62^6 = ~56 billion possible combinations for 6-character codes.
 const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
 
+// aliasCharset defines the characters allowed in a custom alias.
+// It extends the generated-code charset with '-' and '_' so readable slugs like "spring-sale" are possible.
+const aliasCharset = charset + "-_"
+
+// Bounds on the length of a custom alias.
+// The upper bound must stay within the size of the models.Link.ShortCode column.
+const (
+	minAliasLength = 3
+	maxAliasLength = 32
+)
+
+// reservedAliases lists the words that cannot be used as custom aliases because they
+// clash with existing routes (e.g. /health, /api/v1) or could be confused with them.
+// Comparison is case-insensitive.
+var reservedAliases = map[string]bool{
+	"api":     true,
+	"health":  true,
+	"metrics": true,
+	"admin":   true,
+	"static":  true,
+	"assets":  true,
+	"login":   true,
+	"logout":  true,
+}
+
+// Page size bounds for link listings.
+const (
+	defaultListLimit = 20
+	maxListLimit     = 100
+)
+
+// maxInsertAttempts bounds how many generated short codes are tried when inserts
+// keep losing the race for a code against concurrent creations.
+const maxInsertAttempts = 3
+
+// ListLinksOptions describes a link listing request as received from the API or CLI.
+// The zero value lists the 20 most recently created links.
+type ListLinksOptions struct {
+	Filter    repository.LinkListFilter // Domain, creation date range and health status filters
+	SortBy    string                    // repository.SortByCreatedAt (default) or repository.SortByClicks
+	Ascending bool                      // Sort direction; newest/most clicked first by default
+	Limit     int                       // Page size, between 1 and 100 (default 20)
+	Cursor    string                    // Opaque cursor returned as NextCursor by the previous page
+}
+
+// LinkPage is one page of a link listing.
+type LinkPage struct {
+	Links      []repository.LinkWithClicks // Links of this page, in the requested order
+	NextCursor string                      // Cursor of the next page, empty when this is the last page
+}
+
+// CreateLinkOptions groups the optional settings a caller can provide when creating a link.
+// The zero value creates a link with a randomly generated short code.
+type CreateLinkOptions struct {
+	Workspace string     // Slug of the workspace owning the link; models.DefaultWorkspace when empty
+	Alias     string     // Caller-chosen short code (e.g. "spring-sale"); a random code is generated when empty
+	ExpiresAt *time.Time // Moment after which the link stops redirecting; nil means never
+	MaxClicks int        // Click budget after which the link stops redirecting; 0 means unlimited
+	// HTTP status used to redirect (301, 302, 307 or 308); 0 means the server default
+	RedirectStatus int
+	// Return an existing link of the workspace with the same normalized destination and settings
+	// instead of creating a new one; ignored when Alias is set
+	Deduplicate bool
+}
+
 // LinkService provides business logic methods for managing shortened links.
 // It acts as an intermediary between the HTTP handlers and the data repository.
 type LinkService struct {
-	linkRepo repository.LinkRepository // Repository interface for database operations
-
+	linkRepo      repository.LinkRepository      // Repository interface for database operations
+	workspaceRepo repository.WorkspaceRepository // Resolves the short code namespace of a workspace
+	policy        *DestinationPolicy             // Checks new destinations; nil accepts any URL
+	normalizer    *urlnorm.Normalizer            // Canonicalizes new destinations; nil stores them as received
 }
 
 // NewLinkService creates and returns a new instance of LinkService.
 // This is a constructor function following Go conventions.
-func NewLinkService(linkRepo repository.LinkRepository) *LinkService {
+// normalizer and policy are applied, in that order, to the destination of created and updated links;
+// commands that never set a destination (list, stats, delete...) pass nil for both.
+func NewLinkService(linkRepo repository.LinkRepository, workspaceRepo repository.WorkspaceRepository, policy *DestinationPolicy, normalizer *urlnorm.Normalizer) *LinkService {
 	return &LinkService{
-		linkRepo: linkRepo,
+		linkRepo:      linkRepo,
+		workspaceRepo: workspaceRepo,
+		policy:        policy,
+		normalizer:    normalizer,
 	}
 }
 
+// CacheStats returns the counters of the short-code cache, when the link repository is cached.
+// Returns:
+//   - repository.LinkCacheStats: hits, misses, evictions and current size
+//   - bool: false if the service runs without a cache
+func (s *LinkService) CacheStats() (repository.LinkCacheStats, bool) {
+	cached, ok := s.linkRepo.(*repository.CachedLinkRepository)
+	if !ok {
+		return repository.LinkCacheStats{}, false
+	}
+	return cached.Stats(), true
+}
+
 // GenerateShortCode generates a cryptographically secure random short code.
 // Parameters:
 //   - length: the desired length of the generated code
@@ -57,16 +148,241 @@ func (s *LinkService) GenerateShortCode(length int) (string, error) {
 	return string(code), nil
 }
 
+// ValidateAlias checks that a custom alias is well-formed and not reserved.
+// Parameters:
+//   - alias: the caller-chosen short code to validate
+//
+// Returns:
+//   - error: ErrInvalidShortCode if the length or charset is wrong, ErrShortCodeReserved if the word is reserved
+func ValidateAlias(alias string) error {
+	if len(alias) < minAliasLength || len(alias) > maxAliasLength {
+		return fmt.Errorf("%w: alias must be between %d and %d characters", customerrors.ErrInvalidShortCode, minAliasLength, maxAliasLength)
+	}
+	for _, r := range alias {
+		if !strings.ContainsRune(aliasCharset, r) {
+			return fmt.Errorf("%w: alias may only contain letters, digits, '-' and '_'", customerrors.ErrInvalidShortCode)
+		}
+	}
+	if reservedAliases[strings.ToLower(alias)] {
+		return fmt.Errorf("%w: '%s'", customerrors.ErrShortCodeReserved, alias)
+	}
+	return nil
+}
+
+// ValidateRedirectStatus checks that a status code is a redirect the service can issue.
+// 301/308 are permanent (cached by browsers and search engines), 302/307 are temporary;
+// 307/308 additionally preserve the request method and body.
+// Parameters:
+//   - status: the HTTP status code to validate
+//
+// Returns:
+//   - error: ErrInvalidRedirectStatus if the code is not 301, 302, 307 or 308
+func ValidateRedirectStatus(status int) error {
+	switch status {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return nil
+	}
+	return fmt.Errorf("%w: %d (expected 301, 302, 307 or 308)", customerrors.ErrInvalidRedirectStatus, status)
+}
+
+// IsPermanentRedirect reports whether a redirect status is permanent (301 or 308).
+// Browsers cache permanent redirects indefinitely and follow them without contacting the server again.
+func IsPermanentRedirect(status int) bool {
+	return status == http.StatusMovedPermanently || status == http.StatusPermanentRedirect
+}
+
+// ParseOptionalDate parses an optional date given as a query parameter or command flag.
+// Accepts a full RFC 3339 timestamp or a plain YYYY-MM-DD date (midnight UTC).
+// Parameters:
+//   - value: the raw date; empty means no date
+//
+// Returns:
+//   - *time.Time: the parsed instant, or nil when value is empty
+//   - error: if value is neither an RFC 3339 timestamp nor a YYYY-MM-DD date
+func ParseOptionalDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, errors.New("expected RFC 3339 timestamp or YYYY-MM-DD date")
+	}
+	return &t, nil
+}
+
 // CreateLink creates a new shortened link with collision detection and retry logic.
-// This method ensures that each generated short code is unique in the database.
+// This method ensures that each generated short code is unique in the namespace of the owning workspace.
+// When opts.Alias is set, the alias is validated and used as-is instead of a generated code.
+// When opts.Deduplicate is set, an equivalent existing link is returned instead (see findDuplicate).
+// The link redirects to the normalized form of longURL, while longURL itself is kept as OriginalURL.
 // Parameters:
+//   - ctx: context of the caller, used to attach its request ID to the logs
 //   - longURL: the original URL to be shortened
+//   - opts: optional settings such as the workspace, a custom alias, expiration or redirect status
 //
 // Returns:
-//   - *models.Link: the created link with its short code
-//   - error: any error that occurred during creation
-func (s *LinkService) CreateLink(longURL string) (*models.Link, error) {
+//   - *models.Link: the created link with its short code, or the existing link reused by deduplication
+//   - bool: true if a new link was created, false if an existing one was returned
+//   - error: ErrInvalidRedirectStatus for an unknown status or a permanent one on an expiring link,
+//     ErrInvalidURL if longURL cannot be normalized, ErrDestinationBlocked if the destination policy rejects it,
+//     ErrWorkspaceNotFound if the workspace does not exist, or any error that occurred during creation
+func (s *LinkService) CreateLink(ctx context.Context, longURL string, opts CreateLinkOptions) (*models.Link, bool, error) {
 	var shortCode string
+	var err error
+
+	// Reject expiration settings that would create an already-dead link
+	if opts.ExpiresAt != nil && !opts.ExpiresAt.After(time.Now()) {
+		return nil, false, fmt.Errorf("%w: expiration date must be in the future", customerrors.ErrInvalidExpiration)
+	}
+	if opts.MaxClicks < 0 {
+		return nil, false, fmt.Errorf("%w: max clicks cannot be negative", customerrors.ErrInvalidExpiration)
+	}
+	if opts.RedirectStatus != 0 {
+		if err := ValidateRedirectStatus(opts.RedirectStatus); err != nil {
+			return nil, false, err
+		}
+		// Browsers keep permanent redirects with no expiry and stop asking the server, so the link would never expire for them
+		if IsPermanentRedirect(opts.RedirectStatus) && (opts.ExpiresAt != nil || opts.MaxClicks > 0) {
+			return nil, false, fmt.Errorf("%w: %d is permanent and cannot be combined with an expiration date or click budget",
+				customerrors.ErrInvalidRedirectStatus, opts.RedirectStatus)
+		}
+	}
+	destination, err := s.prepareDestination(ctx, longURL)
+	if err != nil {
+		return nil, false, err
+	}
+
+	workspace, err := s.resolveWorkspace(opts.Workspace)
+	if err != nil {
+		return nil, false, err
+	}
+	namespace := workspace.LinkNamespace()
+	longURLHash := urlnorm.Hash(destination)
+
+	if opts.Deduplicate && opts.Alias == "" {
+		existing, err := s.findDuplicate(workspace.Slug, longURLHash, opts)
+		if err != nil {
+			return nil, false, err
+		}
+		if existing != nil {
+			logging.FromContext(ctx).Debug("Returning existing link for duplicate destination",
+				"short_code", existing.ShortCode, "workspace", existing.Workspace)
+			return existing, false, nil
+		}
+	}
+
+	// The availability check and the insert are separate steps, so a concurrent request can still take
+	// the code in between: the unique index then rejects the insert, and generated codes are drawn again
+	for attempt := 1; ; attempt++ {
+		if opts.Alias != "" {
+			shortCode, err = s.reserveAlias(namespace, opts.Alias)
+		} else {
+			shortCode, err = s.generateUniqueShortCode(ctx, namespace)
+		}
+		if err != nil {
+			return nil, false, err
+		}
+
+		// Create a new Link instance with the unique short code
+		link := &models.Link{
+			ShortCode:      shortCode,
+			Workspace:      workspace.Slug,
+			Namespace:      namespace,
+			LongURL:        destination,
+			OriginalURL:    longURL,
+			LongURLHash:    longURLHash,
+			Domain:         models.DomainOf(destination),
+			CreatedAt:      time.Now(), // Set creation timestamp
+			ExpiresAt:      opts.ExpiresAt,
+			MaxClicks:      opts.MaxClicks,
+			RedirectStatus: opts.RedirectStatus,
+			HealthStatus:   models.HealthUnknown, // Not checked by the monitor yet
+		}
+
+		// Persist the new link to the database via the repository layer
+		err = s.linkRepo.CreateLink(link)
+		if err == nil {
+			metrics.LinkCreated()
+			return link, true, nil
+		}
+		if !errors.Is(err, gorm.ErrDuplicatedKey) {
+			return nil, false, fmt.Errorf("failed to create link: %w", err)
+		}
+		if opts.Alias != "" {
+			return nil, false, fmt.Errorf("%w: '%s'", customerrors.ErrShortCodeTaken, opts.Alias)
+		}
+		if attempt == maxInsertAttempts {
+			return nil, false, customerrors.ErrShortCodeGenerationFailed
+		}
+		logging.FromContext(ctx).Warn("Short code taken concurrently, retrying creation",
+			"short_code", shortCode, "attempt", attempt, "max_attempts", maxInsertAttempts)
+	}
+}
+
+// findDuplicate returns the oldest usable link of a workspace that has the same normalized destination
+// as a creation request and would behave the same way: same redirect status, click budget and
+// expiration date, and neither disabled nor expired. Links created with an alias are candidates too.
+// Parameters:
+//   - workspace: the slug of the workspace the link is created in
+//   - longURLHash: the hash of the requested destination
+//   - opts: the settings of the creation request
+//
+// Returns:
+//   - *models.Link: the link to reuse, or nil if a new link must be created
+//   - error: database errors
+func (s *LinkService) findDuplicate(workspace, longURLHash string, opts CreateLinkOptions) (*models.Link, error) {
+	candidates, err := s.linkRepo.FindLinksByLongURLHash(workspace, longURLHash)
+	if err != nil {
+		return nil, err
+	}
+	for i := range candidates {
+		link := &candidates[i]
+		if link.RedirectStatus != opts.RedirectStatus || link.MaxClicks != opts.MaxClicks || !sameTime(link.ExpiresAt, opts.ExpiresAt) {
+			continue
+		}
+		// Disabled or expired links would not redirect: the caller needs a working link
+		err := s.CheckLinkAvailable(link)
+		if err == nil {
+			return link, nil
+		}
+		if !errors.Is(err, customerrors.ErrLinkDisabled) && !errors.Is(err, customerrors.ErrLinkExpired) {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// sameTime reports whether two optional instants are both unset or equal.
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return a.Equal(*b)
+}
+
+// reserveAlias validates a custom alias and makes sure no other link of the namespace already uses it.
+// Returns the alias itself on success, or ErrShortCodeTaken if it is already in use.
+func (s *LinkService) reserveAlias(namespace, alias string) (string, error) {
+	if err := ValidateAlias(alias); err != nil {
+		return "", err
+	}
+
+	exists, err := s.linkRepo.ShortCodeExists(namespace, alias)
+	if err != nil {
+		return "", fmt.Errorf("database error checking alias availability: %w", err)
+	}
+	if exists {
+		return "", fmt.Errorf("%w: '%s'", customerrors.ErrShortCodeTaken, alias)
+	}
+	return alias, nil
+}
+
+// generateUniqueShortCode generates random 6-character codes until one is free in the namespace.
+// Returns ErrShortCodeGenerationFailed if every attempt collides with an existing code.
+func (s *LinkService) generateUniqueShortCode(ctx context.Context, namespace string) (string, error) {
 	maxRetries := 5 // Maximum number of attempts to generate a unique code
 
 	// Retry loop to handle short code collisions
@@ -74,66 +390,336 @@ func (s *LinkService) CreateLink(longURL string) (*models.Link, error) {
 		// Generate a new 6-character short code
 		code, err := s.GenerateShortCode(6)
 		if err != nil {
-			return nil, fmt.Errorf("failed to generate short code: %w", err)
+			return "", fmt.Errorf("failed to generate short code: %w", err)
 		}
 
-		// Check if the generated code already exists in the database
-		_, err = s.linkRepo.GetLinkByShortCode(code)
+		// Check if the generated code already exists in the database (deleted links included)
+		exists, err := s.linkRepo.ShortCodeExists(namespace, code)
 		if err != nil {
-			// If the error is 'record not found', the code is unique and we can use it
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				shortCode = code
-				break // Exit the retry loop - we found a unique code
-			}
-			// If it's any other database error, return it immediately
-			return nil, fmt.Errorf("database error checking short code uniqueness: %w", err)
+			// Any database error is returned immediately
+			return "", fmt.Errorf("database error checking short code uniqueness: %w", err)
+		}
+		if !exists {
+			// The code is unique and we can use it
+			return code, nil
 		}
 
 		// If we reach here, the code already exists (collision detected)
-		log.Printf("Short code '%s' already exists, retrying generation (%d/%d)...", code, i+1, maxRetries)
-	}
-
-	// If we exhausted all retries without finding a unique cod
-	if shortCode == "" {
-		return nil, customerrors.ErrShortCodeGenerationFailed
+		logging.FromContext(ctx).Warn("Short code already exists, retrying generation",
+			"short_code", code, "attempt", i+1, "max_attempts", maxRetries)
 	}
 
-	// Create a new Link instance with the generated unique short code
-	link := &models.Link{
-		ShortCode: shortCode,
-		LongURL:   longURL,
-		CreatedAt: time.Now(), // Set creation timestamp
-	}
+	// We exhausted all retries without finding a unique code
+	return "", customerrors.ErrShortCodeGenerationFailed
+}
 
-	// Persist the new link to the database via the repository layer
-	if err := s.linkRepo.CreateLink(link); err != nil {
-		return nil, fmt.Errorf("failed to create link: %w", err)
+// GetLinkByShortCode retrieves a link from the database using its short code within a namespace.
+// This is the primary method used during URL redirection, where the namespace comes from the URL path.
+// Parameters:
+//   - namespace: the namespace of the short code ("" for /<code>, the workspace slug for /<workspace>/<code>)
+//   - shortCode: the short code to look up
+//
+// Returns:
+//   - *models.Link: the found link
+//   - error: ErrShortCodeNotFound if not found, or other database errors
+func (s *LinkService) GetLinkByShortCode(namespace, shortCode string) (*models.Link, error) {
+	link, err := s.linkRepo.GetLinkByShortCode(namespace, shortCode)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, customerrors.ErrShortCodeNotFound
+		}
+		return nil, err
 	}
 	return link, nil
 }
 
-// GetLinkByShortCode retrieves a link from the database using its short code.
-// This is the primary method used during URL redirection.
+// GetWorkspaceLink retrieves a link owned by a workspace using its short code.
+// This is the lookup used by every management operation: a link of another workspace
+// is reported as not found, exactly like a short code that does not exist.
+// It bypasses the short-code cache, whose entries may predate changes made by other processes.
 // Parameters:
+//   - workspace: the slug of the workspace the caller acts on; models.DefaultWorkspace when empty
 //   - shortCode: the short code to look up
 //
 // Returns:
 //   - *models.Link: the found link
-//   - error: ErrShortCodeNotFound if not found, or other database errors
-func (s *LinkService) GetLinkByShortCode(shortCode string) (*models.Link, error) {
-	link, err := s.linkRepo.GetLinkByShortCode(shortCode)
+//   - error: ErrWorkspaceNotFound, ErrShortCodeNotFound, or other database errors
+func (s *LinkService) GetWorkspaceLink(workspace, shortCode string) (*models.Link, error) {
+	owner, err := s.resolveWorkspace(workspace)
+	if err != nil {
+		return nil, err
+	}
+	link, err := s.storedLinks().GetLinkByShortCode(owner.LinkNamespace(), shortCode)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, customerrors.ErrShortCodeNotFound
 		}
 		return nil, err
 	}
+	if link.Workspace != owner.Slug {
+		return nil, customerrors.ErrShortCodeNotFound
+	}
 	return link, nil
 }
 
+// storedLinks returns the link repository without the short-code cache in front of it, if there is one.
+// Writes still go through s.linkRepo so that they invalidate the cache.
+func (s *LinkService) storedLinks() repository.LinkRepository {
+	if cached, ok := s.linkRepo.(*repository.CachedLinkRepository); ok {
+		return cached.Uncached()
+	}
+	return s.linkRepo
+}
+
+// resolveWorkspace loads a workspace by slug, defaulting to models.DefaultWorkspace.
+// Returns ErrWorkspaceNotFound if no workspace has this slug.
+func (s *LinkService) resolveWorkspace(slug string) (*models.Workspace, error) {
+	if slug == "" {
+		slug = models.DefaultWorkspace
+	}
+	workspace, err := s.workspaceRepo.GetWorkspaceBySlug(slug)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: '%s'", customerrors.ErrWorkspaceNotFound, slug)
+		}
+		return nil, fmt.Errorf("failed to retrieve workspace %s: %w", slug, err)
+	}
+	return workspace, nil
+}
+
+// CheckLinkAvailable verifies that a link can still be used for redirection.
+// The click budget is only looked up when the link has one, so unlimited links cost no extra query.
+// Because clicks are recorded asynchronously, a link under heavy load may slightly exceed its budget.
+// Parameters:
+//   - link: the link to check
+//
+// Returns:
+//   - error: ErrLinkDisabled if the link is turned off, ErrLinkExpired if it is past its date
+//     or out of clicks, or a database error
+func (s *LinkService) CheckLinkAvailable(link *models.Link) error {
+	if link.Disabled {
+		return customerrors.ErrLinkDisabled
+	}
+
+	totalClicks := 0
+	if link.MaxClicks > 0 {
+		count, err := s.linkRepo.CountClicksByLinkID(link.ID)
+		if err != nil {
+			return err
+		}
+		totalClicks = count
+	}
+
+	if link.IsExpired(time.Now(), totalClicks) {
+		return customerrors.ErrLinkExpired
+	}
+	return nil
+}
+
+// NormalizeDestination returns the destination a link created for longURL would redirect to,
+// i.e. longURL after normalization, or longURL itself when the service has no normalizer.
+// Callers comparing URLs (e.g. to deduplicate a batch) should compare these forms, as the stored hash does.
+// Returns ErrInvalidURL if the URL cannot be normalized.
+func (s *LinkService) NormalizeDestination(longURL string) (string, error) {
+	if s.normalizer == nil {
+		return longURL, nil
+	}
+	normalized, err := s.normalizer.Normalize(longURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %s: %v", customerrors.ErrInvalidURL, longURL, err)
+	}
+	return normalized, nil
+}
+
+// prepareDestination normalizes a link destination, if a normalizer is set, and applies the
+// destination policy, if any, to the result: the policy sees the URL the link will actually redirect to.
+// Returns:
+//   - string: the destination to store
+//   - error: ErrInvalidURL if the URL cannot be normalized, or the policy rejection
+func (s *LinkService) prepareDestination(ctx context.Context, longURL string) (string, error) {
+	destination, err := s.NormalizeDestination(longURL)
+	if err != nil {
+		return "", err
+	}
+	if s.policy != nil {
+		if err := s.policy.Check(ctx, destination); err != nil {
+			return "", err
+		}
+	}
+	return destination, nil
+}
+
+// UpdateLongURL changes the destination of an existing link while keeping its short code.
+// The new destination goes through the same normalization and policy as at creation.
+// Parameters:
+//   - ctx: bounds the destination checks
+//   - workspace: the slug of the workspace owning the link
+//   - shortCode: the short code of the link to update
+//   - longURL: the new destination URL
+//
+// Returns:
+//   - *models.Link: the updated link
+//   - error: ErrInvalidURL or ErrDestinationBlocked if longURL is refused,
+//     ErrShortCodeNotFound if the workspace has no such link, or other database errors
+func (s *LinkService) UpdateLongURL(ctx context.Context, workspace, shortCode, longURL string) (*models.Link, error) {
+	destination, err := s.prepareDestination(ctx, longURL)
+	if err != nil {
+		return nil, err
+	}
+	link, err := s.GetWorkspaceLink(workspace, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	// A new destination invalidates the previous domain and health check result
+	link.LongURL = destination
+	link.OriginalURL = longURL
+	link.LongURLHash = urlnorm.Hash(destination)
+	link.Domain = models.DomainOf(destination)
+	link.HealthStatus = models.HealthUnknown
+	link.LastCheckedAt = nil
+	if err := s.linkRepo.UpdateLink(link, "long_url", "original_url", "long_url_hash", "domain", "health_status", "last_checked_at"); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// SetLinkDisabled turns a link off or back on without touching its short code or click history.
+// Parameters:
+//   - workspace: the slug of the workspace owning the link
+//   - shortCode: the short code of the link to change
+//   - disabled: true to stop redirecting, false to resume
+//
+// Returns:
+//   - *models.Link: the updated link
+//   - error: ErrShortCodeNotFound if the workspace has no such link, or other database errors
+func (s *LinkService) SetLinkDisabled(workspace, shortCode string, disabled bool) (*models.Link, error) {
+	link, err := s.GetWorkspaceLink(workspace, shortCode)
+	if err != nil {
+		return nil, err
+	}
+
+	link.Disabled = disabled
+	if err := s.linkRepo.UpdateLink(link, "disabled"); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+// DeleteLink soft-deletes a link: it stops resolving, but its clicks are kept
+// and its short code is never reassigned to another link.
+// Parameters:
+//   - workspace: the slug of the workspace owning the link
+//   - shortCode: the short code of the link to delete
+//
+// Returns:
+//   - error: ErrShortCodeNotFound if the workspace has no such link, or other database errors
+func (s *LinkService) DeleteLink(workspace, shortCode string) error {
+	link, err := s.GetWorkspaceLink(workspace, shortCode)
+	if err != nil {
+		return err
+	}
+	return s.linkRepo.DeleteLink(link)
+}
+
+// ListLinks returns one page of links matching the given filters, sorted and paginated with a cursor.
+// Callers acting on behalf of a workspace must set opts.Filter.Workspace; an empty workspace lists every link.
+// Parameters:
+//   - opts: filters, sort order, page size and the cursor returned by the previous page
+//
+// Returns:
+//   - *LinkPage: the links of the page and the cursor of the next one
+//   - error: ErrInvalidListQuery for a bad cursor, sort key, limit or health filter, or database errors
+func (s *LinkService) ListLinks(opts ListLinksOptions) (*LinkPage, error) {
+	query := repository.LinkListQuery{
+		Filter:     opts.Filter,
+		SortBy:     opts.SortBy,
+		Descending: !opts.Ascending,
+		Limit:      opts.Limit,
+	}
+
+	// Validate and default the query parameters
+	if query.SortBy == "" {
+		query.SortBy = repository.SortByCreatedAt
+	}
+	if query.SortBy != repository.SortByCreatedAt && query.SortBy != repository.SortByClicks {
+		return nil, fmt.Errorf("%w: unknown sort key '%s' (expected '%s' or '%s')",
+			customerrors.ErrInvalidListQuery, query.SortBy, repository.SortByCreatedAt, repository.SortByClicks)
+	}
+	if query.Limit == 0 {
+		query.Limit = defaultListLimit
+	}
+	if query.Limit < 1 || query.Limit > maxListLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", customerrors.ErrInvalidListQuery, maxListLimit)
+	}
+	switch query.Filter.HealthStatus {
+	case "", models.HealthUnknown, models.HealthUp, models.HealthDown:
+	default:
+		return nil, fmt.Errorf("%w: unknown health status '%s'", customerrors.ErrInvalidListQuery, query.Filter.HealthStatus)
+	}
+	query.Filter.Domain = strings.ToLower(strings.TrimSpace(query.Filter.Domain))
+
+	if opts.Cursor != "" {
+		cursor, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		query.After = cursor
+	}
+
+	// Fetch one extra link to know whether another page follows without a separate COUNT query
+	requested := query.Limit
+	query.Limit++
+	links, err := s.linkRepo.ListLinks(query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &LinkPage{Links: links}
+	if len(links) > requested {
+		page.Links = links[:requested]
+		last := page.Links[requested-1]
+		page.NextCursor = encodeCursor(repository.LinkCursor{
+			CreatedAt: last.CreatedAt,
+			Clicks:    last.ClickCount,
+			ID:        last.ID,
+		})
+	}
+	return page, nil
+}
+
+// cursorPayload is the serialized form of a repository.LinkCursor.
+// Short JSON keys keep the base64 cursor compact in URLs.
+type cursorPayload struct {
+	CreatedAt time.Time `json:"c"`
+	Clicks    int       `json:"k"`
+	ID        uint      `json:"i"`
+}
+
+// encodeCursor turns a pagination position into an opaque, URL-safe string.
+func encodeCursor(cursor repository.LinkCursor) string {
+	data, _ := json.Marshal(cursorPayload{CreatedAt: cursor.CreatedAt, Clicks: cursor.Clicks, ID: cursor.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor parses a cursor produced by encodeCursor.
+// Returns ErrInvalidListQuery if the cursor was tampered with or truncated.
+func decodeCursor(encoded string) (*repository.LinkCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", customerrors.ErrInvalidListQuery)
+	}
+	var payload cursorPayload
+	if err := json.Unmarshal(data, &payload); err != nil || payload.ID == 0 {
+		return nil, fmt.Errorf("%w: malformed cursor", customerrors.ErrInvalidListQuery)
+	}
+	return &repository.LinkCursor{CreatedAt: payload.CreatedAt, Clicks: payload.Clicks, ID: payload.ID}, nil
+}
+
 // GetLinkStats retrieves statistics for a given short code.
 // This includes the link details and the total number of clicks recorded.
 // Parameters:
+//   - workspace: the slug of the workspace owning the link
 //   - shortCode: the short code to get statistics for
 //
 // Returns:
@@ -141,13 +727,10 @@ func (s *LinkService) GetLinkByShortCode(shortCode string) (*models.Link, error)
 //   - int: total number of clicks
 //   - error: any error that occurred during retrieval
 
-func (s *LinkService) GetLinkStats(shortCode string) (*models.Link, int, error) {
-	// First, retrieve the link by its shortCode
-	link, err := s.linkRepo.GetLinkByShortCode(shortCode)
+func (s *LinkService) GetLinkStats(workspace, shortCode string) (*models.Link, int, error) {
+	// First, retrieve the link by its shortCode within the workspace
+	link, err := s.GetWorkspaceLink(workspace, shortCode)
 	if err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, 0, customerrors.ErrShortCodeNotFound
-		}
 		return nil, 0, err
 	}
 
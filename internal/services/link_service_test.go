@@ -0,0 +1,183 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	customerrors "github.com/axellelanca/urlshortener/internal/errors"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"gorm.io/gorm"
+)
+
+// racingLinkRepository reports every short code as free, like an availability check
+// that ran just before a concurrent request inserted the same code.
+type racingLinkRepository struct {
+	repository.LinkRepository
+}
+
+func (racingLinkRepository) ShortCodeExists(namespace, shortCode string) (bool, error) {
+	return false, nil
+}
+
+// newTestLinkService builds a LinkService on top of in-memory repositories sharing one store.
+func newTestLinkService(linkRepo repository.LinkRepository, store *repository.MemoryStore) *LinkService {
+	return NewLinkService(linkRepo, repository.NewMemoryWorkspaceRepository(store), nil, nil)
+}
+
+func TestCreateLinkAliasLostRaceReturnsTaken(t *testing.T) {
+	store := repository.NewMemoryStore()
+	memoryRepo := repository.NewMemoryLinkRepository(store)
+	if _, _, err := newTestLinkService(memoryRepo, store).CreateLink(context.Background(), "https://example.com/a", CreateLinkOptions{Alias: "spring-sale"}); err != nil {
+		t.Fatalf("first creation failed: %v", err)
+	}
+
+	service := newTestLinkService(racingLinkRepository{memoryRepo}, store)
+	_, _, err := service.CreateLink(context.Background(), "https://example.com/b", CreateLinkOptions{Alias: "spring-sale"})
+	if !errors.Is(err, customerrors.ErrShortCodeTaken) {
+		t.Fatalf("expected ErrShortCodeTaken, got %v", err)
+	}
+}
+
+// collidingLinkRepository rejects the first inserts as duplicates, like a unique index
+// hit by codes that concurrent requests took between the check and the insert.
+type collidingLinkRepository struct {
+	repository.LinkRepository
+	collisions int
+}
+
+func (r *collidingLinkRepository) CreateLink(link *models.Link) error {
+	if r.collisions > 0 {
+		r.collisions--
+		return fmt.Errorf("failed to create link: %w", gorm.ErrDuplicatedKey)
+	}
+	return r.LinkRepository.CreateLink(link)
+}
+
+func TestCreateLinkGeneratedCodeLostRaceRetries(t *testing.T) {
+	store := repository.NewMemoryStore()
+	linkRepo := &collidingLinkRepository{LinkRepository: repository.NewMemoryLinkRepository(store), collisions: maxInsertAttempts - 1}
+	service := newTestLinkService(linkRepo, store)
+
+	link, created, err := service.CreateLink(context.Background(), "https://example.com", CreateLinkOptions{})
+	if err != nil || !created {
+		t.Fatalf("expected a new link, got created=%v err=%v", created, err)
+	}
+	if link.ID == 0 {
+		t.Fatal("link was not stored")
+	}
+
+	linkRepo.collisions = maxInsertAttempts
+	_, _, err = service.CreateLink(context.Background(), "https://example.com", CreateLinkOptions{})
+	if !errors.Is(err, customerrors.ErrShortCodeGenerationFailed) {
+		t.Fatalf("expected ErrShortCodeGenerationFailed, got %v", err)
+	}
+}
+
+func TestListLinksCursor(t *testing.T) {
+	store := repository.NewMemoryStore()
+	service := newTestLinkService(repository.NewMemoryLinkRepository(store), store)
+	for i := 0; i < 5; i++ {
+		if _, _, err := service.CreateLink(context.Background(), fmt.Sprintf("https://example.com/%d", i), CreateLinkOptions{}); err != nil {
+			t.Fatalf("creation failed: %v", err)
+		}
+	}
+
+	seen := map[uint]bool{}
+	opts := ListLinksOptions{Limit: 2}
+	for pages := 1; ; pages++ {
+		page, err := service.ListLinks(opts)
+		if err != nil {
+			t.Fatalf("ListLinks failed: %v", err)
+		}
+		for _, link := range page.Links {
+			if seen[link.ID] {
+				t.Fatalf("link %d returned twice", link.ID)
+			}
+			seen[link.ID] = true
+		}
+		if page.NextCursor == "" {
+			if pages != 3 || len(seen) != 5 {
+				t.Fatalf("got %d links in %d pages, want 5 in 3", len(seen), pages)
+			}
+			break
+		}
+		opts.Cursor = page.NextCursor
+	}
+
+	for _, cursor := range []string{"not base64!", "bm90IGpzb24", "e30"} {
+		if _, err := service.ListLinks(ListLinksOptions{Cursor: cursor}); !errors.Is(err, customerrors.ErrInvalidListQuery) {
+			t.Errorf("cursor %q: expected ErrInvalidListQuery, got %v", cursor, err)
+		}
+	}
+}
+
+func TestParseOptionalDate(t *testing.T) {
+	if date, err := ParseOptionalDate(""); date != nil || err != nil {
+		t.Errorf("empty value: got %v, %v", date, err)
+	}
+	date, err := ParseOptionalDate("2025-03-01")
+	if err != nil || !date.Equal(time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("plain date: got %v, %v", date, err)
+	}
+	date, err = ParseOptionalDate("2025-03-01T10:30:00+02:00")
+	if err != nil || !date.Equal(time.Date(2025, 3, 1, 8, 30, 0, 0, time.UTC)) {
+		t.Errorf("RFC 3339 timestamp: got %v, %v", date, err)
+	}
+	if _, err := ParseOptionalDate("01/03/2025"); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
+
+func TestCreateLinkRejectsPermanentRedirectOnExpiringLink(t *testing.T) {
+	store := repository.NewMemoryStore()
+	service := newTestLinkService(repository.NewMemoryLinkRepository(store), store)
+	expiresAt := time.Now().Add(time.Hour)
+
+	for _, opts := range []CreateLinkOptions{
+		{RedirectStatus: 301, ExpiresAt: &expiresAt},
+		{RedirectStatus: 308, MaxClicks: 10},
+	} {
+		if _, _, err := service.CreateLink(context.Background(), "https://example.com", opts); !errors.Is(err, customerrors.ErrInvalidRedirectStatus) {
+			t.Errorf("status %d: expected ErrInvalidRedirectStatus, got %v", opts.RedirectStatus, err)
+		}
+	}
+	if _, _, err := service.CreateLink(context.Background(), "https://example.com", CreateLinkOptions{RedirectStatus: 307, MaxClicks: 10}); err != nil {
+		t.Errorf("temporary redirect with a click budget: unexpected error %v", err)
+	}
+}
+
+func TestManagementWritesDoNotRestoreCachedValues(t *testing.T) {
+	store := repository.NewMemoryStore()
+	stored := repository.NewMemoryLinkRepository(store)
+	cached := repository.NewCachedLinkRepository(stored, repository.LinkCacheOptions{Size: 10, TTL: time.Minute})
+	service := newTestLinkService(cached, store)
+
+	link, _, err := service.CreateLink(context.Background(), "https://example.com/old", CreateLinkOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A redirect warms the cache, then another process (the CLI) changes the destination
+	if _, err := service.GetLinkByShortCode("", link.ShortCode); err != nil {
+		t.Fatal(err)
+	}
+	otherProcess := newTestLinkService(stored, store)
+	if _, err := otherProcess.UpdateLongURL(context.Background(), "", link.ShortCode, "https://example.com/new"); err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := service.SetLinkDisabled("", link.ShortCode, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := stored.GetLinkByShortCode("", link.ShortCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.LongURL != "https://example.com/new" || current.LongURL != "https://example.com/new" || !current.Disabled {
+		t.Errorf("stale cached link written back: returned %s, stored %s (disabled=%v)", updated.LongURL, current.LongURL, current.Disabled)
+	}
+}
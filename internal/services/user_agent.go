@@ -0,0 +1,207 @@
+package services
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// Device classes returned by ParseUserAgent.
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+)
+
+// topUserAgentsLimit is the number of raw user agents reported in a breakdown.
+const topUserAgentsLimit = 10
+
+// UserAgentInfo is the classification of a raw User-Agent header.
+type UserAgentInfo struct {
+	Browser string // Browser family (e.g. "Chrome"), or the bot name for bots
+	OS      string // Operating system family (e.g. "Android")
+	Device  string // DeviceDesktop, DeviceMobile, DeviceTablet or DeviceBot
+}
+
+// IsBot reports whether the user agent belongs to a crawler, script or other automated client.
+func (i UserAgentInfo) IsBot() bool {
+	return i.Device == DeviceBot
+}
+
+// uaRule maps a set of substrings to a name. A rule matches when any of its tokens is found.
+type uaRule struct {
+	name   string
+	tokens []string
+}
+
+// botRules identify well-known crawlers and HTTP libraries. They are checked before
+// any browser rule because many bots also advertise a browser engine.
+// Tokens are matched against the lowercased user agent.
+var botRules = []uaRule{
+	{"Googlebot", []string{"googlebot", "google-inspectiontool", "adsbot-google"}},
+	{"Bingbot", []string{"bingbot", "bingpreview"}},
+	{"Yandex", []string{"yandexbot"}},
+	{"Baidu", []string{"baiduspider"}},
+	{"DuckDuckGo", []string{"duckduckbot"}},
+	{"Facebook", []string{"facebookexternalhit", "facebot"}},
+	{"Twitter", []string{"twitterbot"}},
+	{"LinkedIn", []string{"linkedinbot"}},
+	{"Slack", []string{"slackbot", "slack-imgproxy"}},
+	{"Discord", []string{"discordbot"}},
+	{"WhatsApp", []string{"whatsapp"}},
+	{"Telegram", []string{"telegrambot"}},
+	{"curl", []string{"curl/"}},
+	{"Wget", []string{"wget/"}},
+	{"Python", []string{"python-requests", "python-urllib", "aiohttp"}},
+	{"Go", []string{"go-http-client"}},
+	{"Java", []string{"java/", "okhttp", "apache-httpclient"}},
+	{"Headless browser", []string{"headlesschrome", "phantomjs"}},
+	{"Other bot", []string{"bot", "crawler", "spider", "slurp", "preview", "monitor", "scanner", "fetcher"}},
+}
+
+// browserRules identify browser families. Order matters: Chromium-based browsers
+// also contain "Chrome/" and almost every browser contains "Safari/".
+// Tokens are matched against the original user agent.
+var browserRules = []uaRule{
+	{"Edge", []string{"Edg/", "EdgA/", "EdgiOS/", "Edge/"}},
+	{"Opera", []string{"OPR/", "Opera"}},
+	{"Samsung Internet", []string{"SamsungBrowser/"}},
+	{"Firefox", []string{"Firefox/", "FxiOS/"}},
+	{"Chrome", []string{"Chrome/", "CriOS/", "Chromium/"}},
+	{"Safari", []string{"Safari/"}},
+	{"Internet Explorer", []string{"MSIE ", "Trident/"}},
+}
+
+// osRules identify operating system families. iOS must come before macOS
+// ("like Mac OS X") and Android/ChromeOS before Linux.
+var osRules = []uaRule{
+	{"Windows", []string{"Windows"}},
+	{"iOS", []string{"iPhone", "iPad", "iPod"}},
+	{"Android", []string{"Android"}},
+	{"ChromeOS", []string{"CrOS"}},
+	{"macOS", []string{"Macintosh", "Mac OS X"}},
+	{"Linux", []string{"Linux", "X11"}},
+}
+
+// ParseUserAgent classifies a raw User-Agent header into browser, OS and device class.
+// It relies on substring rules rather than a full parser: good enough for aggregate
+// statistics, with unknown values reported as "Other". An empty header is treated as a bot,
+// since real browsers always send one.
+func ParseUserAgent(ua string) UserAgentInfo {
+	if strings.TrimSpace(ua) == "" {
+		return UserAgentInfo{Browser: "Unknown", OS: "Other", Device: DeviceBot}
+	}
+
+	if bot := matchRule(botRules, strings.ToLower(ua)); bot != "" {
+		return UserAgentInfo{Browser: bot, OS: orOther(matchRule(osRules, ua)), Device: DeviceBot}
+	}
+
+	info := UserAgentInfo{
+		Browser: orOther(matchRule(browserRules, ua)),
+		OS:      orOther(matchRule(osRules, ua)),
+		Device:  DeviceDesktop,
+	}
+	switch {
+	case strings.Contains(ua, "iPad") || strings.Contains(ua, "Tablet") ||
+		(strings.Contains(ua, "Android") && !strings.Contains(ua, "Mobile")):
+		info.Device = DeviceTablet
+	case strings.Contains(ua, "Mobi") || strings.Contains(ua, "iPhone") || strings.Contains(ua, "iPod"):
+		info.Device = DeviceMobile
+	}
+	return info
+}
+
+// matchRule returns the name of the first rule with a token contained in s, or "" if none matches.
+func matchRule(rules []uaRule, s string) string {
+	for _, rule := range rules {
+		for _, token := range rule.tokens {
+			if strings.Contains(s, token) {
+				return rule.name
+			}
+		}
+	}
+	return ""
+}
+
+// orOther replaces an empty classification with "Other".
+func orOther(name string) string {
+	if name == "" {
+		return "Other"
+	}
+	return name
+}
+
+// UserAgentBreakdown summarizes the clients that clicked a link.
+// Browser, OS and device counts only include human clicks; bot traffic is reported in Bots.
+type UserAgentBreakdown struct {
+	HumanClicks      int                     `json:"human_clicks"`
+	BotClicks        int                     `json:"bot_clicks"`
+	Browsers         []repository.GroupCount `json:"browsers"`
+	OperatingSystems []repository.GroupCount `json:"operating_systems"`
+	Devices          []repository.GroupCount `json:"devices"`
+	Bots             []repository.GroupCount `json:"bots"`
+	TopUserAgents    []repository.GroupCount `json:"top_user_agents"`
+}
+
+// GetUserAgentBreakdown classifies the clicks of a link by browser, OS and device class.
+// The raw user agents are grouped in the database, then each distinct value is classified once.
+// Parameters:
+//   - linkID: the database ID of the link
+//
+// Returns:
+//   - *UserAgentBreakdown: human/bot split, per-family counts and the most frequent raw user agents
+//   - error: any error that occurred during retrieval
+func (s *ClickService) GetUserAgentBreakdown(linkID uint) (*UserAgentBreakdown, error) {
+	rawCounts, err := s.clickRepo.CountClicksByUserAgent(linkID)
+	if err != nil {
+		return nil, err
+	}
+
+	breakdown := &UserAgentBreakdown{}
+	browsers := map[string]int{}
+	systems := map[string]int{}
+	devices := map[string]int{}
+	bots := map[string]int{}
+
+	for _, raw := range rawCounts {
+		info := ParseUserAgent(raw.Value)
+		if info.IsBot() {
+			breakdown.BotClicks += raw.Count
+			bots[info.Browser] += raw.Count
+			continue
+		}
+		breakdown.HumanClicks += raw.Count
+		browsers[info.Browser] += raw.Count
+		systems[info.OS] += raw.Count
+		devices[info.Device] += raw.Count
+	}
+
+	breakdown.Browsers = sortedCounts(browsers)
+	breakdown.OperatingSystems = sortedCounts(systems)
+	breakdown.Devices = sortedCounts(devices)
+	breakdown.Bots = sortedCounts(bots)
+
+	// The repository already returns raw user agents most frequent first
+	breakdown.TopUserAgents = rawCounts
+	if len(rawCounts) > topUserAgentsLimit {
+		breakdown.TopUserAgents = rawCounts[:topUserAgentsLimit]
+	}
+	return breakdown, nil
+}
+
+// sortedCounts converts a name->count map to a slice ordered by decreasing count, then by name.
+func sortedCounts(counts map[string]int) []repository.GroupCount {
+	result := make([]repository.GroupCount, 0, len(counts))
+	for value, count := range counts {
+		result = append(result, repository.GroupCount{Value: value, Count: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count != result[j].Count {
+			return result[i].Count > result[j].Count
+		}
+		return result[i].Value < result[j].Value
+	})
+	return result
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	customerrors "github.com/axellelanca/urlshortener/internal/errors"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+	"gorm.io/gorm"
+)
+
+// WorkspaceService provides business logic methods for managing workspaces.
+type WorkspaceService struct {
+	workspaceRepo repository.WorkspaceRepository // Repository interface for workspace data operations
+}
+
+// NewWorkspaceService creates and returns a new instance of WorkspaceService.
+func NewWorkspaceService(workspaceRepo repository.WorkspaceRepository) *WorkspaceService {
+	return &WorkspaceService{workspaceRepo: workspaceRepo}
+}
+
+// CreateWorkspace creates a new workspace.
+// The slug follows the custom alias rules (ValidateAlias) because namespaced workspaces
+// use it as the first segment of their short URLs (/<slug>/<code>).
+// Parameters:
+//   - slug: unique identifier of the workspace (e.g. "acme")
+//   - name: human-readable label; the slug is used when empty
+//   - namespacedCodes: give the workspace its own short code namespace; cannot be changed later
+//
+// Returns:
+//   - *models.Workspace: the created workspace
+//   - error: ErrInvalidWorkspace if the slug is malformed or reserved, ErrWorkspaceTaken if it is used, or a database error
+func (s *WorkspaceService) CreateWorkspace(slug, name string, namespacedCodes bool) (*models.Workspace, error) {
+	slug = strings.TrimSpace(slug)
+	if err := ValidateAlias(slug); err != nil {
+		return nil, fmt.Errorf("%w: %v", customerrors.ErrInvalidWorkspace, err)
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = slug
+	}
+
+	if _, err := s.workspaceRepo.GetWorkspaceBySlug(slug); err == nil {
+		return nil, fmt.Errorf("%w: '%s'", customerrors.ErrWorkspaceTaken, slug)
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check workspace %s: %w", slug, err)
+	}
+
+	workspace := &models.Workspace{Slug: slug, Name: name, NamespacedCodes: namespacedCodes}
+	if err := s.workspaceRepo.CreateWorkspace(workspace); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// GetWorkspace retrieves a workspace by its slug.
+// Returns ErrWorkspaceNotFound if no workspace has this slug.
+func (s *WorkspaceService) GetWorkspace(slug string) (*models.Workspace, error) {
+	workspace, err := s.workspaceRepo.GetWorkspaceBySlug(slug)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: '%s'", customerrors.ErrWorkspaceNotFound, slug)
+		}
+		return nil, fmt.Errorf("failed to retrieve workspace %s: %w", slug, err)
+	}
+	return workspace, nil
+}
+
+// ListWorkspaces returns every workspace, oldest first.
+func (s *WorkspaceService) ListWorkspaces() ([]models.Workspace, error) {
+	workspaces, err := s.workspaceRepo.ListWorkspaces()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workspaces: %w", err)
+	}
+	return workspaces, nil
+}
@@ -0,0 +1,225 @@
+package urlnorm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/idna"
+)
+
+// defaultPorts maps the schemes whose default port is redundant in a URL.
+var defaultPorts = map[string]string{
+	"http":  "80",
+	"https": "443",
+}
+
+// Options selects the rewriting rules applied by a Normalizer.
+// Each rule keeps the destination of the URL unchanged, except StripTrackingParams which
+// drops parameters only used by analytics tools.
+type Options struct {
+	LowercaseHost       bool     // Lowercase the host name (net/url already lowercases the scheme)
+	StripDefaultPort    bool     // Remove ":80" from http and ":443" from https URLs
+	CleanPath           bool     // Resolve "." and ".." path segments (RFC 3986) and use "/" for an empty path
+	IDNToASCII          bool     // Convert internationalized host names to their ASCII (punycode) form
+	StripTrackingParams bool     // Remove the query parameters listed in TrackingParams
+	TrackingParams      []string // Tracking parameter names; a trailing '*' matches a prefix (e.g. "utm_*")
+}
+
+// Normalizer rewrites URLs into a canonical form according to its Options.
+// It is immutable and safe for concurrent use.
+type Normalizer struct {
+	options          Options
+	trackingNames    map[string]bool // Exact tracking parameter names
+	trackingPrefixes []string        // Prefixes of the "name*" tracking parameter patterns
+}
+
+// New creates a Normalizer applying the given rules.
+// Parameters:
+//   - options: the rules to apply
+//
+// Returns:
+//   - *Normalizer: normalizer ready for concurrent use
+func New(options Options) *Normalizer {
+	n := &Normalizer{options: options, trackingNames: make(map[string]bool)}
+	for _, param := range options.TrackingParams {
+		param = strings.TrimSpace(param)
+		if prefix, ok := strings.CutSuffix(param, "*"); ok {
+			n.trackingPrefixes = append(n.trackingPrefixes, prefix)
+		} else if param != "" {
+			n.trackingNames[param] = true
+		}
+	}
+	return n
+}
+
+// Normalize returns the canonical form of a URL, e.g. "HTTP://Example.com:80/a/../b?utm_source=x"
+// becomes "http://example.com/b" with every rule enabled.
+// URLs without a host (e.g. "mailto:" or relative URLs) are returned unchanged: there is nothing to canonicalize.
+// Parameters:
+//   - rawURL: the URL to normalize
+//
+// Returns:
+//   - string: the normalized URL
+//   - error: if the URL cannot be parsed or its internationalized host name is invalid
+func (n *Normalizer) Normalize(rawURL string) (string, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Host == "" {
+		return rawURL, nil
+	}
+
+	host, port := parsed.Hostname(), parsed.Port()
+	if n.options.IDNToASCII && !isASCII(host) {
+		if host, err = idna.Lookup.ToASCII(host); err != nil {
+			return "", fmt.Errorf("invalid internationalized host name: %w", err)
+		}
+	}
+	if n.options.LowercaseHost {
+		host = strings.ToLower(host)
+	}
+	if n.options.StripDefaultPort && port == defaultPorts[parsed.Scheme] {
+		port = ""
+	}
+	parsed.Host = joinHostPort(host, port)
+
+	if n.options.CleanPath {
+		cleaned := removeDotSegments(parsed.EscapedPath())
+		if cleaned == "" {
+			cleaned = "/"
+		}
+		if parsed.Path, err = url.PathUnescape(cleaned); err != nil {
+			return "", err
+		}
+		parsed.RawPath = cleaned
+	}
+
+	if n.options.StripTrackingParams && parsed.RawQuery != "" {
+		parsed.RawQuery = n.stripTrackingParams(parsed.RawQuery)
+	}
+	return parsed.String(), nil
+}
+
+// stripTrackingParams removes the tracking parameters from a raw query string.
+// The query is edited as text rather than through url.Values so the remaining parameters
+// keep their order and encoding.
+func (n *Normalizer) stripTrackingParams(rawQuery string) string {
+	params := strings.Split(rawQuery, "&")
+	kept := params[:0]
+	for _, param := range params {
+		name, _, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if !n.isTrackingParam(name) {
+			kept = append(kept, param)
+		}
+	}
+	return strings.Join(kept, "&")
+}
+
+// isTrackingParam reports whether a query parameter name matches the tracking parameter patterns.
+func (n *Normalizer) isTrackingParam(name string) bool {
+	if n.trackingNames[name] {
+		return true
+	}
+	for _, prefix := range n.trackingPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Key returns the comparison form of a URL: two URLs with the same key lead to the same resource.
+// Only spelling differences that cannot change the destination are removed: the case of the
+// scheme and host, a trailing dot on the host, the default port and an empty path ("/").
+// The path, query and fragment are kept as-is since servers may treat them case-sensitively.
+// A URL that cannot be parsed is its own key.
+// Parameters:
+//   - rawURL: the URL to normalize
+//
+// Returns:
+//   - string: the normalized URL
+func Key(rawURL string) string {
+	parsed, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsed.Scheme == "" || parsed.Host == "" {
+		return rawURL
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	host, port := parsed.Hostname(), parsed.Port()
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if port == defaultPorts[parsed.Scheme] {
+		port = ""
+	}
+	parsed.Host = joinHostPort(host, port)
+	if parsed.Path == "" && parsed.Opaque == "" {
+		parsed.Path = "/"
+	}
+	return parsed.String()
+}
+
+// Hash returns the hex-encoded SHA-256 of Key(rawURL).
+// It is stored with each link (models.Link.LongURLHash) so identical destinations
+// can be found through an index instead of comparing every long URL.
+// Parameters:
+//   - rawURL: the URL to hash
+//
+// Returns:
+//   - string: 64 hexadecimal characters
+func Hash(rawURL string) string {
+	sum := sha256.Sum256([]byte(Key(rawURL)))
+	return hex.EncodeToString(sum[:])
+}
+
+// joinHostPort rebuilds the host part of a URL, restoring the brackets of IPv6 literals.
+// An empty port is left out.
+func joinHostPort(host, port string) string {
+	if port == "" && !strings.Contains(host, ":") {
+		return host
+	}
+	return strings.TrimSuffix(net.JoinHostPort(host, port), ":")
+}
+
+// removeDotSegments resolves the "." and ".." segments of an escaped path as described in
+// RFC 3986 section 5.2.4. Unlike path.Clean it keeps empty segments ("//") and trailing slashes,
+// which servers are free to treat as significant.
+func removeDotSegments(escapedPath string) string {
+	segments := strings.Split(escapedPath, "/")
+	kept := make([]string, 0, len(segments))
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		switch segment {
+		case ".":
+		case "..":
+			// The first kept segment is the empty one before the leading slash; it is never removed
+			if len(kept) > 1 {
+				kept = kept[:len(kept)-1]
+			}
+		default:
+			kept = append(kept, segment)
+			continue
+		}
+		// "/a/b/.." designates the directory "/a/", so the trailing slash is kept
+		if last {
+			kept = append(kept, "")
+		}
+	}
+	return strings.Join(kept, "/")
+}
+
+// isASCII reports whether s only contains ASCII characters.
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= 0x80 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,125 @@
+package urlnorm
+
+import "testing"
+
+// allRules enables every normalization rule, with the usual tracking parameters.
+var allRules = Options{
+	LowercaseHost:       true,
+	StripDefaultPort:    true,
+	CleanPath:           true,
+	IDNToASCII:          true,
+	StripTrackingParams: true,
+	TrackingParams:      []string{"utm_*", "fbclid", " gclid "},
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"HTTP://Example.com:80/a/../b?utm_source=x", "http://example.com/b"},
+		{"https://Example.COM:443", "https://example.com/"},
+		{"https://example.com:8443/", "https://example.com:8443/"},
+		{"http://example.com:443/", "http://example.com:443/"},
+		{"https://[2001:DB8::1]:443/x", "https://[2001:db8::1]/x"},
+		{"https://[2001:db8::1]:8080/x", "https://[2001:db8::1]:8080/x"},
+		{"https://bücher.example/", "https://xn--bcher-kva.example/"},
+		{"https://example.com/a/./b/../c/", "https://example.com/a/c/"},
+		{"https://example.com/a/b/..", "https://example.com/a/"},
+		{"https://example.com/a//b/", "https://example.com/a//b/"},
+		{"https://example.com/../../a", "https://example.com/a"},
+		{"https://example.com/Case/Path", "https://example.com/Case/Path"},
+		{"https://example.com/a%2Fb/../c", "https://example.com/c"},
+		{"https://example.com/%7Euser/a%20b", "https://example.com/%7Euser/a%20b"},
+		{"https://example.com/?b=2&utm_medium=m&a=1&fbclid=f&gclid=g", "https://example.com/?b=2&a=1"},
+		{"https://example.com/?utm_source=x", "https://example.com/"},
+		{"https://example.com/?utm%5Fsource=x&q=1", "https://example.com/?q=1"},
+		{"https://example.com/?utmost=1", "https://example.com/?utmost=1"},
+		{"https://example.com/#Fragment", "https://example.com/#Fragment"},
+		{"mailto:Someone@Example.com", "mailto:Someone@Example.com"},
+		{"/relative/../path", "/relative/../path"},
+	}
+	normalizer := New(allRules)
+	for _, tt := range tests {
+		got, err := normalizer.Normalize(tt.in)
+		if err != nil {
+			t.Errorf("Normalize(%q) failed: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeAppliesOnlyEnabledRules(t *testing.T) {
+	in := "HTTPS://Example.com:443/a/../b?utm_source=x"
+	got, err := New(Options{}).Normalize(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// net/url always lowercases the scheme; everything else is left as received
+	if want := "https://Example.com:443/a/../b?utm_source=x"; got != want {
+		t.Errorf("Normalize without rules = %q, want %q", got, want)
+	}
+}
+
+func TestNormalizeRejectsInvalidURLs(t *testing.T) {
+	normalizer := New(allRules)
+	for _, in := range []string{"http://exa mple.com/", "https://%zz/", "https://xn--a.example/\x7f"} {
+		if got, err := normalizer.Normalize(in); err == nil {
+			t.Errorf("Normalize(%q) = %q, expected an error", in, got)
+		}
+	}
+}
+
+func TestRemoveDotSegments(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		// Examples of RFC 3986 section 5.2.4
+		{"/a/b/c/./../../g", "/a/g"},
+		{"mid/content=5/../6", "mid/6"},
+		{"", ""},
+		{"/", "/"},
+		{"/.", "/"},
+		{"/..", "/"},
+		{"/a/.", "/a/"},
+		{"/a/..", "/"},
+		{"/a/b/../", "/a/"},
+		{"/a/./b", "/a/b"},
+		{"/../../a", "/a"},
+		{"/a//../b", "/a/b"},
+		{"/a//b/", "/a//b/"},
+		{"/a/..b/.c", "/a/..b/.c"},
+	}
+	for _, tt := range tests {
+		if got := removeDotSegments(tt.in); got != tt.want {
+			t.Errorf("removeDotSegments(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestKeyAndHash(t *testing.T) {
+	equivalent := []string{
+		"https://example.com",
+		"HTTPS://EXAMPLE.COM/",
+		"https://example.com.:443/",
+		" https://example.com/ ",
+	}
+	for _, url := range equivalent {
+		if Key(url) != "https://example.com/" {
+			t.Errorf("Key(%q) = %q", url, Key(url))
+		}
+		if Hash(url) != Hash(equivalent[0]) {
+			t.Errorf("Hash(%q) differs from Hash(%q)", url, equivalent[0])
+		}
+	}
+	if Hash("https://example.com/Path") == Hash("https://example.com/path") {
+		t.Error("paths differing by case share a hash")
+	}
+	if len(Hash("anything")) != 64 {
+		t.Error("hash is not 64 hexadecimal characters")
+	}
+}
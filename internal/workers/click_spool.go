@@ -0,0 +1,338 @@
+package workers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/axellelanca/urlshortener/internal/models"
+)
+
+// replaySuffix is appended to the spool path to name the file being replayed.
+// New overflow events keep going to the spool file while this one is drained.
+const replaySuffix = ".replay"
+
+// pressurePollInterval is how often the replayer checks whether the click channel has room again.
+const pressurePollInterval = 10 * time.Millisecond
+
+// ClickSpoolStats is a snapshot of the spool counters, exposed for monitoring.
+type ClickSpoolStats struct {
+	Enabled  bool   `json:"enabled"`  // Whether overflow events are written to disk
+	Spooled  uint64 `json:"spooled"`  // Events written to the spool file because the channel was full
+	Replayed uint64 `json:"replayed"` // Spooled events fed back into the click channel
+	Dropped  uint64 `json:"dropped"`  // Events lost: spool disabled, write failure or unreadable spool line
+}
+
+// ClickSpool is a durable overflow buffer for click events.
+// When the click channel is full, RedirectHandler appends the event to a local append-only
+// file (one JSON event per line) instead of discarding it; a background replayer feeds the
+// spooled events back into the worker pool once the channel has room again, including
+// events left over from a previous run. Delivery is at-least-once: if the process dies while
+// a spool file is being replayed, the events of that file already replayed are sent again.
+type ClickSpool struct {
+	path   string       // Spool file path; empty disables spooling (events are counted as dropped)
+	logger *slog.Logger // Logger tagging every line with component=click_spool
+
+	mu   sync.Mutex // Guards file
+	file *os.File   // Spool file opened in append mode
+
+	spooled  atomic.Uint64 // Counters reported by Stats
+	replayed atomic.Uint64
+	dropped  atomic.Uint64
+}
+
+// NewClickSpool opens (or creates) the spool file at the given path.
+// Parameters:
+//   - path: location of the spool file; an empty path returns a disabled spool that only counts drops
+//
+// Returns:
+//   - *ClickSpool: spool ready to receive overflow events
+//   - error: if the spool file cannot be opened
+func NewClickSpool(path string) (*ClickSpool, error) {
+	spool := &ClickSpool{path: path, logger: slog.Default().With("component", "click_spool")}
+	if path == "" {
+		return spool, nil
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open click spool %s: %w", path, err)
+	}
+	spool.file = file
+	return spool, nil
+}
+
+// Append writes a click event that could not be enqueued to the spool file.
+// Failures are logged and counted as dropped events; they never affect the redirect.
+// Parameters:
+//   - event: the click event to keep for later processing
+func (s *ClickSpool) Append(event models.ClickEvent) {
+	if s.path == "" {
+		s.dropped.Add(1)
+		s.logger.Warn("Click spool disabled, dropping click event", "request_id", event.RequestID, "link_id", event.LinkID)
+		return
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		s.dropped.Add(1)
+		s.logger.Error("Failed to encode click event", "request_id", event.RequestID, "link_id", event.LinkID, "error", err)
+		return
+	}
+	line = append(line, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(line); err != nil {
+		s.dropped.Add(1)
+		s.logger.Error("Failed to spool click event", "request_id", event.RequestID, "link_id", event.LinkID, "error", err)
+		return
+	}
+	s.spooled.Add(1)
+}
+
+// Drain moves the events still queued in the click channel to the spool, without blocking.
+// It is used on shutdown when the workers did not empty the channel in time, so those events
+// are replayed on the next startup instead of being lost. The channel may be open or closed.
+// Parameters:
+//   - clickEventsChan: the channel consumed by the click workers
+//
+// Returns:
+//   - int: number of events taken from the channel and appended to the spool
+func (s *ClickSpool) Drain(clickEventsChan <-chan models.ClickEvent) int {
+	drained := 0
+	for {
+		select {
+		case event, ok := <-clickEventsChan:
+			if !ok {
+				return drained
+			}
+			s.Append(event)
+			drained++
+		default:
+			return drained
+		}
+	}
+}
+
+// Stats returns a snapshot of the spool counters.
+func (s *ClickSpool) Stats() ClickSpoolStats {
+	return ClickSpoolStats{
+		Enabled:  s.path != "",
+		Spooled:  s.spooled.Load(),
+		Replayed: s.replayed.Load(),
+		Dropped:  s.dropped.Load(),
+	}
+}
+
+// Close closes the spool file. Events still in it are replayed on the next startup.
+func (s *ClickSpool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil
+	}
+	err := s.file.Close()
+	s.file = nil
+	return err
+}
+
+// StartReplayer launches the background goroutine that feeds spooled events back into the click channel.
+// It runs immediately (to pick up events left over from a previous run) and then at every interval,
+// until the context is cancelled; events not replayed by then stay on disk for the next startup.
+// Events are only replayed while the channel is less than half full, so live clicks keep priority.
+// Parameters:
+//   - ctx: stops the replayer when cancelled
+//   - clickEventsChan: the channel consumed by the click workers
+//   - interval: time between two replay attempts
+//
+// Returns:
+//   - <-chan struct{}: closed once the replayer has stopped sending to clickEventsChan
+func (s *ClickSpool) StartReplayer(ctx context.Context, clickEventsChan chan<- models.ClickEvent, interval time.Duration) <-chan struct{} {
+	done := make(chan struct{})
+	if s.path == "" {
+		close(done)
+		return done
+	}
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			if err := s.replay(ctx, clickEventsChan, interval); err != nil {
+				s.logger.Error("Click spool replay failed", "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return done
+}
+
+// replay drains the replay file into the channel while there is room.
+// When the channel stays busy for longer than maxWait or the context is cancelled, the events
+// not yet replayed are written back to the replay file and the next call resumes from there.
+func (s *ClickSpool) replay(ctx context.Context, clickEventsChan chan<- models.ClickEvent, maxWait time.Duration) error {
+	if !waitForRoom(ctx, clickEventsChan, maxWait) {
+		return nil
+	}
+
+	replayPath := s.path + replaySuffix
+	if err := s.rotate(replayPath); err != nil {
+		return err
+	}
+
+	file, err := os.Open(replayPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil // Nothing spooled
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", replayPath, err)
+	}
+	defer file.Close()
+
+	reader := bufio.NewReader(file)
+	var replayed uint64
+	for {
+		line, readErr := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			var event models.ClickEvent
+			if err := json.Unmarshal(line, &event); err != nil {
+				// Typically a line cut short by a crash during Append
+				s.dropped.Add(1)
+				s.logger.Warn("Skipping unreadable click spool line", "error", err)
+			} else if !s.send(ctx, clickEventsChan, event, maxWait) {
+				// Keep this line and everything after it for the next attempt
+				remaining := io.MultiReader(bytes.NewReader(line), reader)
+				if err := rewrite(replayPath, remaining); err != nil {
+					return err
+				}
+				s.logger.Info("Click spool replay paused (click channel busy or shutting down)", "replayed", replayed)
+				return nil
+			} else {
+				replayed++
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return fmt.Errorf("failed to read %s: %w", replayPath, readErr)
+		}
+	}
+
+	if err := os.Remove(replayPath); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", replayPath, err)
+	}
+	if replayed > 0 {
+		s.logger.Info("Click spool replayed", "replayed", replayed)
+	}
+	return nil
+}
+
+// rotate moves the current spool file aside for replay and starts a new empty spool file,
+// unless a previous replay file is still pending or the spool file is empty.
+func (s *ClickSpool) rotate(replayPath string) error {
+	if _, err := os.Stat(replayPath); err == nil {
+		return nil // Finish the pending replay file first
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.file == nil {
+		return nil // Spool closed
+	}
+	info, err := s.file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat click spool: %w", err)
+	}
+	if info.Size() == 0 {
+		return nil
+	}
+
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close click spool: %w", err)
+	}
+	s.file = nil
+	if err := os.Rename(s.path, replayPath); err != nil {
+		return fmt.Errorf("failed to rotate click spool: %w", err)
+	}
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to reopen click spool %s: %w", s.path, err)
+	}
+	s.file = file
+	return nil
+}
+
+// underPressure reports whether the click channel is at least half full.
+// An unbuffered channel is never considered under pressure: replayed sends simply wait for a worker.
+func underPressure(clickEventsChan chan<- models.ClickEvent) bool {
+	return cap(clickEventsChan) > 0 && 2*len(clickEventsChan) >= cap(clickEventsChan)
+}
+
+// send feeds one spooled event into the click channel once it has room.
+// Returns false, without sending, if the channel stays busy for maxWait or the context is cancelled.
+func (s *ClickSpool) send(ctx context.Context, clickEventsChan chan<- models.ClickEvent, event models.ClickEvent, maxWait time.Duration) bool {
+	if !waitForRoom(ctx, clickEventsChan, maxWait) {
+		return false
+	}
+	select {
+	case clickEventsChan <- event:
+		s.replayed.Add(1)
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// waitForRoom waits until the click channel is no longer under pressure, polling for at most maxWait.
+// Returns false if the channel is still busy after maxWait or the context is cancelled.
+func waitForRoom(ctx context.Context, clickEventsChan chan<- models.ClickEvent, maxWait time.Duration) bool {
+	deadline := time.Now().Add(maxWait)
+	for underPressure(clickEventsChan) {
+		if time.Now().After(deadline) {
+			return false
+		}
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(pressurePollInterval):
+		}
+	}
+	return ctx.Err() == nil
+}
+
+// rewrite atomically replaces a file with the content of a reader.
+func rewrite(path string, content io.Reader) error {
+	tmpPath := path + ".tmp"
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", tmpPath, err)
+	}
+	if _, err := io.Copy(tmp, content); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %w", tmpPath, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmpPath, err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
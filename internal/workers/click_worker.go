@@ -1,55 +1,250 @@
 package workers
 
 import (
-	"log"
+	"context"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/axellelanca/urlshortener/internal/metrics"
 	"github.com/axellelanca/urlshortener/internal/models"
 	"github.com/axellelanca/urlshortener/internal/repository"
+	"github.com/axellelanca/urlshortener/internal/services"
 )
 
+// WorkerOptions groups the optional processing steps applied by click workers
+// before a click is persisted, and the batching thresholds used to persist them.
+type WorkerOptions struct {
+	GeoLocator    services.GeoLocator // Resolves IPs to country/region; nil disables geolocation
+	VisitorSalt   string              // Secret mixed into visitor hashes for unique-visitor counting
+	BatchSize     int                 // Number of clicks accumulated before a multi-row insert (1 or less = one insert per click)
+	FlushInterval time.Duration       // Maximum time a click waits in a partial batch before being flushed
+	RetryAttempts int                 // Number of attempts to write a batch before giving up on it (at least 1)
+	RetryBackoff  time.Duration       // Delay before the first retry, doubled after each failed attempt
+	DeadLetters   *DeadLetterStore    // Receives the clicks that still fail after retries; nil only logs them
+}
+
+// maxRetryBackoff caps the exponential backoff between two write attempts.
+const maxRetryBackoff = 10 * time.Second
+
+// ClickWorkerPool tracks the click worker goroutines so the server can wait for them on shutdown.
+type ClickWorkerPool struct {
+	wg sync.WaitGroup // Done when every worker has flushed its last batch and exited
+
+	pending   atomic.Int64  // Events received by workers but not yet written (sitting in a batch)
+	persisted atomic.Uint64 // Clicks successfully written since startup
+
+	logger *slog.Logger // Logger tagging every line with component=click_worker
+}
+
 // StartClickWorkers launches a pool of worker goroutines to process click events asynchronously.
 // This implements the worker pool pattern to handle high-volume click tracking without blocking.
 // Parameters:
 //   - workerCount: number of concurrent workers to spawn
-//   - clickEventsChan: channel that receives click events to be processed
+//   - clickEventsChan: channel that receives click events to be processed; closing it stops the workers
 //   - clickRepo: repository interface for persisting clicks to database
-func StartClickWorkers(workerCount int, clickEventsChan <-chan models.ClickEvent, clickRepo repository.ClickRepository) {
-	log.Printf("Starting %d click worker(s)...", workerCount)
+//   - opts: optional enrichment steps (geolocation, visitor hashing) and batching thresholds
+//
+// Returns:
+//   - *ClickWorkerPool: handle used to wait for the workers to drain the channel on shutdown
+func StartClickWorkers(workerCount int, clickEventsChan <-chan models.ClickEvent, clickRepo repository.ClickRepository, opts WorkerOptions) *ClickWorkerPool {
+	pool := &ClickWorkerPool{logger: slog.Default().With("component", "click_worker")}
+	pool.logger.Info("Starting click workers", "workers", workerCount, "batch_size", opts.BatchSize, "flush_interval", opts.FlushInterval)
 
 	// Spawn the specified number of worker goroutines
 	// Each worker will listen on the same channel and process events concurrently
 	for i := 0; i < workerCount; i++ {
-		go clickWorker(clickEventsChan, clickRepo)
+		pool.wg.Add(1)
+		go func() {
+			defer pool.wg.Done()
+			pool.clickWorker(clickEventsChan, clickRepo, opts)
+		}()
+	}
+	return pool
+}
+
+// Pending returns the number of events held in worker batches and not yet written.
+func (p *ClickWorkerPool) Pending() int {
+	return int(p.pending.Load())
+}
+
+// Persisted returns the number of clicks successfully written since startup.
+func (p *ClickWorkerPool) Persisted() uint64 {
+	return p.persisted.Load()
+}
+
+// Wait blocks until every worker has exited (after the channel was closed) or the context is done.
+// Parameters:
+//   - ctx: bounds the wait, typically the shutdown timeout
+//
+// Returns:
+//   - error: nil if all workers finished, or the context error if they were still running
+func (p *ClickWorkerPool) Wait(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		p.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
 // clickWorker is the function executed by each worker goroutine.
-// It continuously listens for click events on the channel and processes them.
-// When the channel is closed, the worker will exit gracefully.
-func clickWorker(clickEventsChan <-chan models.ClickEvent, clickRepo repository.ClickRepository) {
-	// Range over the channel - this will block until events arrive
-	// When the channel is closed, the loop will exit and the goroutine will terminate
-	for event := range clickEventsChan {
-		// Convert the ClickEvent (which might be a lightweight event struct)
-		// into a full Click model that matches our database schema
-		click := &models.Click{
-			LinkID:    event.LinkID,    // Which shortened link was clicked
-			Timestamp: event.Timestamp, // When the click occurred
-			UserAgent: event.UserAgent, // Browser/client information for analytics
-			IPAddress: event.IPAddress, // Client IP for geolocation/analytics
-		}
+// It accumulates click events and persists them with one multi-row insert when the batch is full
+// or when the flush interval elapses, whichever comes first, so bursts do not turn into one INSERT per click.
+// When the channel is closed, the pending batch is flushed and the worker exits gracefully.
+func (p *ClickWorkerPool) clickWorker(clickEventsChan <-chan models.ClickEvent, clickRepo repository.ClickRepository, opts WorkerOptions) {
+	batchSize := opts.BatchSize
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	flushInterval := opts.FlushInterval
+	if flushInterval <= 0 {
+		flushInterval = time.Second
+	}
 
-		// Persist the click to the database via the repository
-		// This is the actual database write operation
-		if err := clickRepo.CreateClick(click); err != nil {
+	batch := make([]models.Click, 0, batchSize)
+	requestIDs := make([]string, 0, batchSize) // Request ID of each click of the batch, for log correlation
+	ticker := time.NewTicker(flushInterval)
+	defer ticker.Stop()
+
+	// flush persists the pending batch and starts a new one
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		start := time.Now()
+		saved := len(batch)
+		if err := writeWithRetry(p.logger, clickRepo, batch, opts); err != nil {
 			// Log error but don't crash - we want to continue processing other clicks
-			// In production, you might want to add retry logic or dead letter queues
-			log.Printf("ERROR: Failed to save click for LinkID %d (UserAgent: %s, IP: %s): %v",
-				event.LinkID, event.UserAgent, event.IPAddress, err)
+			// The batch is split so one bad click cannot take the others down with it
+			p.logger.Error("Failed to save batch of clicks after retries", "clicks", len(batch), "error", err)
+			saved = saveIndividually(p.logger, clickRepo, batch, requestIDs, opts)
 		} else {
-			// Success case - click was recorded successfully
-			log.Printf("Click recorded successfully for LinkID %d", event.LinkID)
+			p.logger.Debug("Clicks recorded successfully", "clicks", len(batch), "request_ids", requestIDs)
+		}
+		p.persisted.Add(uint64(saved))
+		metrics.ObserveClickWrite(time.Since(start), saved)
+		p.pending.Add(-int64(len(batch)))
+		batch = make([]models.Click, 0, batchSize)
+		requestIDs = make([]string, 0, batchSize)
+	}
+
+	for {
+		select {
+		case event, ok := <-clickEventsChan:
+			if !ok {
+				// Channel closed during shutdown: persist what is left before exiting
+				flush()
+				return
+			}
+			p.pending.Add(1)
+			batch = append(batch, newClick(p.logger, event, opts))
+			requestIDs = append(requestIDs, event.RequestID)
+			if len(batch) >= batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			// Time threshold: do not keep clicks of a quiet period waiting for a full batch
+			flush()
 		}
 	}
-	// Worker exits when channel is closed - this happens during graceful shutdown
+}
+
+// writeWithRetry inserts a batch, retrying with exponential backoff on failure.
+// Parameters:
+//   - logger: logger receiving the retry warnings
+//   - clickRepo: repository used to insert the clicks
+//   - batch: the clicks to insert
+//   - opts: number of attempts and initial backoff
+//
+// Returns:
+//   - error: the error of the last attempt if every attempt failed
+func writeWithRetry(logger *slog.Logger, clickRepo repository.ClickRepository, batch []models.Click, opts WorkerOptions) error {
+	attempts := opts.RetryAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	backoff := opts.RetryBackoff
+
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = clickRepo.CreateClicks(batch); err == nil {
+			return nil
+		}
+		metrics.ClickWriteFailed()
+		if attempt == attempts {
+			break
+		}
+		logger.Warn("Failed to save clicks, retrying",
+			"attempt", attempt, "max_attempts", attempts, "clicks", len(batch), "retry_in", backoff, "error", err)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+	return err
+}
+
+// saveIndividually inserts the clicks of a failed batch one by one and dead-letters those that still fail.
+// requestIDs holds the request ID of each click of the batch, logged with the clicks that fail.
+// Returns the number of clicks that were saved.
+func saveIndividually(logger *slog.Logger, clickRepo repository.ClickRepository, batch []models.Click, requestIDs []string, opts WorkerOptions) int {
+	saved := 0
+	var letters []DeadLetter
+	for i := range batch {
+		click := batch[i]
+		if err := clickRepo.CreateClick(&click); err != nil {
+			metrics.ClickWriteFailed()
+			logger.Warn("Failed to save click", "request_id", requestIDs[i], "link_id", click.LinkID, "error", err)
+			letters = append(letters, newDeadLetter(click, err, max(opts.RetryAttempts, 1)+1))
+			continue
+		}
+		saved++
+	}
+	if len(letters) == 0 {
+		return saved
+	}
+	metrics.ClicksDeadLettered(len(letters))
+
+	if opts.DeadLetters == nil {
+		logger.Error("Clicks lost, no dead-letter store configured", "clicks", len(letters))
+	} else if err := opts.DeadLetters.Add(letters...); err != nil {
+		logger.Error("Clicks lost, failed to write dead letters", "clicks", len(letters), "error", err)
+	} else {
+		logger.Warn("Clicks moved to the dead-letter file, replay them with 'clicks replay-dead-letters'", "clicks", len(letters))
+	}
+	return saved
+}
+
+// newClick converts a ClickEvent (a lightweight event struct) into a full Click model
+// that matches our database schema, applying the enrichment steps enabled in opts.
+func newClick(logger *slog.Logger, event models.ClickEvent, opts WorkerOptions) models.Click {
+	click := models.Click{
+		LinkID:    event.LinkID,    // Which shortened link was clicked
+		Timestamp: event.Timestamp, // When the click occurred
+		UserAgent: event.UserAgent, // Browser/client information for analytics
+		IPAddress: event.IPAddress, // Client IP for geolocation/analytics
+		Referrer:  event.Referrer,  // Traffic source for channel analytics
+		// Anonymous per-day visitor identifier for unique-visitor counting
+		VisitorHash: services.VisitorHash(opts.VisitorSalt, event.IPAddress, event.UserAgent, event.Timestamp),
+	}
+
+	// Resolve the client IP to a country/region when geolocation is enabled
+	// A failed lookup only loses the location, never the click itself
+	if opts.GeoLocator != nil {
+		location, err := opts.GeoLocator.Lookup(event.IPAddress)
+		if err != nil {
+			logger.Warn("Geolocation failed", "request_id", event.RequestID, "ip", event.IPAddress, "error", err)
+		}
+		click.Country = location.Country
+		click.Region = location.Region
+	}
+
+	// Over-long user agents would otherwise fail the whole batch on PostgreSQL, at every retry
+	click.FitColumns()
+	return click
 }
@@ -0,0 +1,189 @@
+package workers
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	customerrors "github.com/axellelanca/urlshortener/internal/errors"
+	"github.com/axellelanca/urlshortener/internal/models"
+	"github.com/axellelanca/urlshortener/internal/repository"
+)
+
+// replayingSuffix is appended to the dead-letter path while 'clicks replay-dead-letters' processes it.
+// The server keeps appending new dead letters to the main file in the meantime.
+const replayingSuffix = ".replaying"
+
+// DeadLetter is a click that could not be written to the database even after retries.
+// It keeps every enriched field of the click so a replay stores exactly what would have been stored.
+type DeadLetter struct {
+	LinkID      uint      `json:"link_id"`
+	Timestamp   time.Time `json:"timestamp"`
+	UserAgent   string    `json:"user_agent"`
+	IPAddress   string    `json:"ip_address"`
+	Country     string    `json:"country,omitempty"`
+	Region      string    `json:"region,omitempty"`
+	Referrer    string    `json:"referrer,omitempty"`
+	VisitorHash string    `json:"visitor_hash,omitempty"`
+	Reason      string    `json:"reason"`    // ErrClickRecordingFailed message of the last failure
+	Attempts    int       `json:"attempts"`  // Number of write attempts made so far
+	FailedAt    time.Time `json:"failed_at"` // Time of the last failure
+}
+
+// Click converts the dead letter back into the Click model to insert.
+// Fields are fitted to their columns, so clicks dead-lettered for being too long can be replayed.
+func (d DeadLetter) Click() models.Click {
+	click := models.Click{
+		LinkID:      d.LinkID,
+		Timestamp:   d.Timestamp,
+		UserAgent:   d.UserAgent,
+		IPAddress:   d.IPAddress,
+		Country:     d.Country,
+		Region:      d.Region,
+		Referrer:    d.Referrer,
+		VisitorHash: d.VisitorHash,
+	}
+	click.FitColumns()
+	return click
+}
+
+// newDeadLetter records why a click could not be written, as an ErrClickRecordingFailed reason.
+func newDeadLetter(click models.Click, cause error, attempts int) DeadLetter {
+	reason := customerrors.ErrClickRecordingFailed{LinkID: click.LinkID, Reason: cause.Error()}
+	return DeadLetter{
+		LinkID:      click.LinkID,
+		Timestamp:   click.Timestamp,
+		UserAgent:   click.UserAgent,
+		IPAddress:   click.IPAddress,
+		Country:     click.Country,
+		Region:      click.Region,
+		Referrer:    click.Referrer,
+		VisitorHash: click.VisitorHash,
+		Reason:      reason.Error(),
+		Attempts:    attempts,
+		FailedAt:    time.Now().UTC(),
+	}
+}
+
+// DeadLetterStore keeps clicks whose writes failed permanently in a local append-only file
+// (one JSON DeadLetter per line). A file is used rather than a table because the most common
+// cause of failed writes is the database itself being unavailable.
+type DeadLetterStore struct {
+	path string     // Dead-letter file path; empty disables the store (failed clicks are only logged)
+	mu   sync.Mutex // Serializes appends from concurrent workers
+}
+
+// NewDeadLetterStore creates a store writing to the given file.
+// The file is opened for each append, so it can be moved aside by a replay while the server runs.
+// Parameters:
+//   - path: location of the dead-letter file; empty disables dead-lettering
+//
+// Returns:
+//   - *DeadLetterStore: store ready to receive failed clicks
+func NewDeadLetterStore(path string) *DeadLetterStore {
+	return &DeadLetterStore{path: path}
+}
+
+// Add appends dead letters to the file.
+// Parameters:
+//   - letters: the failed clicks to keep
+//
+// Returns:
+//   - error: if the store is disabled or the file cannot be written
+func (s *DeadLetterStore) Add(letters ...DeadLetter) error {
+	if s.path == "" {
+		return errors.New("dead-letter store disabled")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open dead-letter file %s: %w", s.path, err)
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	for _, letter := range letters {
+		if err := encoder.Encode(letter); err != nil {
+			return fmt.Errorf("failed to encode dead letter for link %d: %w", letter.LinkID, err)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write dead-letter file %s: %w", s.path, err)
+	}
+	return nil
+}
+
+// Replay inserts every dead letter into the database, one click at a time so that a click
+// failing again does not block the others. Clicks failing again are appended back to the
+// dead-letter file with their attempt count incremented and the new reason.
+// A replay interrupted by a crash leaves a '.replaying' file that is processed again first next time,
+// so clicks already inserted from it would be inserted twice.
+// Parameters:
+//   - clickRepo: repository used to insert the clicks
+//
+// Returns:
+//   - replayed: number of clicks successfully inserted
+//   - failed: number of clicks written back to the dead-letter file
+//   - error: if the dead-letter file cannot be read or rewritten
+func (s *DeadLetterStore) Replay(clickRepo repository.ClickRepository) (replayed int, failed int, err error) {
+	if s.path == "" {
+		return 0, 0, errors.New("dead-letter store disabled")
+	}
+
+	// Move the file aside so the server can keep appending while we replay
+	replayingPath := s.path + replayingSuffix
+	if _, statErr := os.Stat(replayingPath); errors.Is(statErr, os.ErrNotExist) {
+		renameErr := os.Rename(s.path, replayingPath)
+		if errors.Is(renameErr, os.ErrNotExist) {
+			return 0, 0, nil // Nothing to replay
+		}
+		if renameErr != nil {
+			return 0, 0, fmt.Errorf("failed to move dead-letter file aside: %w", renameErr)
+		}
+	}
+
+	file, err := os.Open(replayingPath)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to open %s: %w", replayingPath, err)
+	}
+	defer file.Close()
+
+	var stillFailing []DeadLetter
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var letter DeadLetter
+		if err := json.Unmarshal(scanner.Bytes(), &letter); err != nil {
+			slog.Warn("Skipping unreadable dead-letter line", "error", err)
+			continue
+		}
+		click := letter.Click()
+		if err := clickRepo.CreateClick(&click); err != nil {
+			stillFailing = append(stillFailing, newDeadLetter(click, err, letter.Attempts+1))
+			continue
+		}
+		replayed++
+	}
+	if err := scanner.Err(); err != nil {
+		return replayed, 0, fmt.Errorf("failed to read %s: %w", replayingPath, err)
+	}
+
+	if len(stillFailing) > 0 {
+		if err := s.Add(stillFailing...); err != nil {
+			return replayed, len(stillFailing), err
+		}
+	}
+	if err := os.Remove(replayingPath); err != nil {
+		return replayed, len(stillFailing), fmt.Errorf("failed to remove %s: %w", replayingPath, err)
+	}
+	return replayed, len(stillFailing), nil
+}